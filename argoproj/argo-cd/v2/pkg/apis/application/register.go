@@ -0,0 +1,24 @@
+package application
+
+// API Group
+const (
+	Group = "argoproj.io"
+)
+
+// Application constants
+const (
+	ApplicationKind      = "Application"
+	ApplicationSingular  = "application"
+	ApplicationPlural    = "applications"
+	ApplicationShortName = "app"
+	ApplicationFullName  = ApplicationPlural + "." + Group
+)
+
+// AppProject constants
+const (
+	AppProjectKind      = "AppProject"
+	AppProjectSingular  = "appproject"
+	AppProjectPlural    = "appprojects"
+	AppProjectShortName = "appproj"
+	AppProjectFullName  = AppProjectPlural + "." + Group
+)
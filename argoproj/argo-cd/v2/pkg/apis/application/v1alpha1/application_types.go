@@ -588,18 +588,88 @@ const (
 // OperationPhase is the phase of an operation
 type OperationPhase string
 
+const (
+	// OperationRunning indicates the operation is in progress
+	OperationRunning OperationPhase = "Running"
+	// OperationTerminating indicates the operation was asked to stop and is cleaning up
+	OperationTerminating OperationPhase = "Terminating"
+	// OperationFailed indicates the operation completed, but at least one resource or hook failed
+	OperationFailed OperationPhase = "Failed"
+	// OperationError indicates the operation could not be carried out, e.g. because manifests failed to generate
+	OperationError OperationPhase = "Error"
+	// OperationSucceeded indicates the operation completed successfully
+	OperationSucceeded OperationPhase = "Succeeded"
+)
+
 // ResultCode is the result of a sync operation on a specific resource
 type ResultCode string
 
+const (
+	// ResultCodeSynced indicates the resource was applied
+	ResultCodeSynced ResultCode = "Synced"
+	// ResultCodeSyncFailed indicates the resource could not be applied
+	ResultCodeSyncFailed ResultCode = "SyncFailed"
+	// ResultCodePruned indicates the resource was deleted
+	ResultCodePruned ResultCode = "Pruned"
+	// ResultCodePruneSkipped indicates the resource requires pruning, but pruning was disabled
+	ResultCodePruneSkipped ResultCode = "PruneSkipped"
+	// ResultCodePruneConfirm indicates the resource requires pruning, but is waiting for confirmation
+	ResultCodePruneConfirm ResultCode = "PruneConfirm"
+)
+
 // HookType is the type of a hook
 type HookType string
 
+const (
+	// HookTypePreSync hooks run before the resources are applied
+	HookTypePreSync HookType = "PreSync"
+	// HookTypeSync hooks run together with the resources
+	HookTypeSync HookType = "Sync"
+	// HookTypePostSync hooks run after all resources are applied and healthy
+	HookTypePostSync HookType = "PostSync"
+	// HookTypeSkip marks a resource that is not applied at all
+	HookTypeSkip HookType = "Skip"
+	// HookTypeSyncFail hooks run when the sync operation fails
+	HookTypeSyncFail HookType = "SyncFail"
+	// HookTypePostDelete hooks run after the application is deleted
+	HookTypePostDelete HookType = "PostDelete"
+)
+
 // SyncPhase is the phase of a sync operation
 type SyncPhase string
 
+const (
+	// SyncPhasePreSync runs the PreSync hooks
+	SyncPhasePreSync SyncPhase = "PreSync"
+	// SyncPhaseSync applies the resources and runs the Sync hooks
+	SyncPhaseSync SyncPhase = "Sync"
+	// SyncPhasePostSync runs the PostSync hooks once all resources are healthy
+	SyncPhasePostSync SyncPhase = "PostSync"
+	// SyncPhaseSyncFail runs the SyncFail hooks after a failed sync
+	SyncPhaseSyncFail SyncPhase = "SyncFail"
+	// SyncPhasePostDelete runs the PostDelete hooks after the application is deleted
+	SyncPhasePostDelete SyncPhase = "PostDelete"
+)
+
 // HealthStatusCode is the health status of a resource
 type HealthStatusCode string
 
+// Possible health statuses
+const (
+	// HealthStatusUnknown indicates that the health assessment failed and the actual health status is unknown
+	HealthStatusUnknown HealthStatusCode = "Unknown"
+	// HealthStatusProgressing indicates that the resource is not healthy yet but still making progress and might become healthy soon
+	HealthStatusProgressing HealthStatusCode = "Progressing"
+	// HealthStatusHealthy indicates that the resource is 100% healthy
+	HealthStatusHealthy HealthStatusCode = "Healthy"
+	// HealthStatusSuspended indicates that the resource is suspended and waiting for some external event to resume (e.g. suspended CronJob or paused Deployment)
+	HealthStatusSuspended HealthStatusCode = "Suspended"
+	// HealthStatusDegraded indicates that the resource status indicates a failure or the resource could not reach a healthy state within some timeout
+	HealthStatusDegraded HealthStatusCode = "Degraded"
+	// HealthStatusMissing indicates that the resource is missing in the cluster
+	HealthStatusMissing HealthStatusCode = "Missing"
+)
+
 // OperationInitiator contains information about the initiator of an operation
 type OperationInitiator struct {
 	// Username contains the name of a user who started operation
@@ -0,0 +1,176 @@
+package v1alpha1
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionSeverity is the severity of an application condition, derived from its type
+type ConditionSeverity string
+
+const (
+	// ConditionSeverityError is the severity of conditions that report a failure, such as ComparisonError
+	ConditionSeverityError ConditionSeverity = "Error"
+	// ConditionSeverityWarning is the severity of conditions that point out a problem, such as OrphanedResourceWarning
+	ConditionSeverityWarning ConditionSeverity = "Warning"
+	// ConditionSeverityInfo is the severity of purely informational conditions
+	ConditionSeverityInfo ConditionSeverity = "Info"
+	// ConditionSeverityUnknown is the severity of condition types that follow no naming convention
+	ConditionSeverityUnknown ConditionSeverity = ""
+)
+
+// ConditionSeverityOf returns the severity of a condition type. Argo CD's condition types carry the severity
+// as suffix, such as SyncError, so the suffix is checked first and the prefix second.
+func ConditionSeverityOf(conditionType ApplicationConditionType) ConditionSeverity {
+	for _, severity := range []ConditionSeverity{ConditionSeverityError, ConditionSeverityWarning, ConditionSeverityInfo} {
+		if strings.HasSuffix(conditionType, string(severity)) {
+			return severity
+		}
+	}
+	for _, severity := range []ConditionSeverity{ConditionSeverityError, ConditionSeverityWarning, ConditionSeverityInfo} {
+		if strings.HasPrefix(conditionType, string(severity)) {
+			return severity
+		}
+	}
+	return ConditionSeverityUnknown
+}
+
+// Severity returns the severity of the condition
+func (c *ApplicationCondition) Severity() ConditionSeverity {
+	return ConditionSeverityOf(c.Type)
+}
+
+// IsError returns true if the condition is an error
+func (c *ApplicationCondition) IsError() bool {
+	return c.Severity() == ConditionSeverityError
+}
+
+// IsWarning returns true if the condition is a warning
+func (c *ApplicationCondition) IsWarning() bool {
+	return c.Severity() == ConditionSeverityWarning
+}
+
+// SetConditions replaces all conditions whose type is in evaluatedTypes with the given conditions, the same
+// way the Argo CD controller does. Conditions of other types are kept. A condition whose type and message
+// did not change keeps its LastTransitionTime, all others get the current time unless they have one. The
+// result is sorted by type.
+func (status *ApplicationStatus) SetConditions(conditions []ApplicationCondition, evaluatedTypes map[ApplicationConditionType]bool) {
+	now := metav1.Now()
+	result := make([]ApplicationCondition, 0, len(status.Conditions)+len(conditions))
+	for _, condition := range status.Conditions {
+		if evaluatedTypes[condition.Type] {
+			continue
+		}
+		if condition.LastTransitionTime == nil {
+			condition.LastTransitionTime = &now
+		}
+		result = append(result, condition)
+	}
+	for _, condition := range conditions {
+		if existing := findCondition(status.Conditions, condition.Type, condition.Message); existing != nil && existing.LastTransitionTime != nil {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		} else if condition.LastTransitionTime == nil {
+			condition.LastTransitionTime = &now
+		}
+		result = append(result, condition)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+	status.Conditions = result
+}
+
+// SetCondition replaces the conditions of the type of condition with condition, see SetConditions
+func (status *ApplicationStatus) SetCondition(condition ApplicationCondition) {
+	status.SetConditions([]ApplicationCondition{condition}, map[ApplicationConditionType]bool{condition.Type: true})
+}
+
+// ClearConditions removes all conditions of the given types
+func (status *ApplicationStatus) ClearConditions(conditionTypes ...ApplicationConditionType) {
+	evaluatedTypes := map[ApplicationConditionType]bool{}
+	for _, t := range conditionTypes {
+		evaluatedTypes[t] = true
+	}
+	status.SetConditions(nil, evaluatedTypes)
+}
+
+// GetConditions returns the conditions of the given types, or all conditions if conditionTypes is empty
+func (status *ApplicationStatus) GetConditions(conditionTypes map[ApplicationConditionType]bool) []ApplicationCondition {
+	var result []ApplicationCondition
+	for _, condition := range status.Conditions {
+		if len(conditionTypes) == 0 || conditionTypes[condition.Type] {
+			result = append(result, condition)
+		}
+	}
+	return result
+}
+
+// GetErrorConditions returns the conditions with error severity
+func (status *ApplicationStatus) GetErrorConditions() []ApplicationCondition {
+	return filterConditions(status.Conditions, ConditionSeverityError)
+}
+
+// GetWarningConditions returns the conditions with warning severity
+func (status *ApplicationStatus) GetWarningConditions() []ApplicationCondition {
+	return filterConditions(status.Conditions, ConditionSeverityWarning)
+}
+
+func filterConditions(conditions []ApplicationCondition, severity ConditionSeverity) []ApplicationCondition {
+	var result []ApplicationCondition
+	for _, condition := range conditions {
+		if condition.Severity() == severity {
+			result = append(result, condition)
+		}
+	}
+	return result
+}
+
+func findCondition(conditions []ApplicationCondition, conditionType ApplicationConditionType, message string) *ApplicationCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType && conditions[i].Message == message {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// DiffConditions compares two lists of conditions by type and message, ignoring LastTransitionTime. A
+// condition whose message changed is returned in changed with its new message, unless its type occurs more
+// often in one list than in the other. All results are sorted by type and message.
+func DiffConditions(oldConditions, newConditions []ApplicationCondition) (added, changed, removed []ApplicationCondition) {
+	remaining := sortedConditions(oldConditions)
+	for _, condition := range sortedConditions(newConditions) {
+		if i := slices.IndexFunc(remaining, func(c ApplicationCondition) bool {
+			return c.Type == condition.Type && c.Message == condition.Message
+		}); i >= 0 {
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			continue
+		}
+		added = append(added, condition)
+	}
+	removed = remaining
+
+	// pair the added and removed conditions of the same type as changed
+	var unpaired []ApplicationCondition
+	for _, condition := range added {
+		if i := slices.IndexFunc(removed, func(c ApplicationCondition) bool { return c.Type == condition.Type }); i >= 0 {
+			removed = append(removed[:i], removed[i+1:]...)
+			changed = append(changed, condition)
+			continue
+		}
+		unpaired = append(unpaired, condition)
+	}
+	return unpaired, changed, removed
+}
+
+func sortedConditions(conditions []ApplicationCondition) []ApplicationCondition {
+	sorted := append([]ApplicationCondition(nil), conditions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		return sorted[i].Message < sorted[j].Message
+	})
+	return sorted
+}
@@ -0,0 +1,136 @@
+package v1alpha1
+
+import (
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ResourcesFinalizerName is the finalizer that makes Argo CD delete the resources of an application before
+	// the application itself, using foreground propagation
+	ResourcesFinalizerName = "resources-finalizer.argocd.argoproj.io"
+	// ForegroundPropagationPolicyFinalizer deletes the resources of an application with foreground propagation
+	ForegroundPropagationPolicyFinalizer = ResourcesFinalizerName + "/foreground"
+	// BackgroundPropagationPolicyFinalizer deletes the resources of an application with background propagation
+	BackgroundPropagationPolicyFinalizer = ResourcesFinalizerName + "/background"
+
+	// PostDeleteFinalizerName is the finalizer that makes Argo CD run the PostDelete hooks of an application
+	// once its resources are deleted
+	PostDeleteFinalizerName = "post-delete-finalizer.argocd.argoproj.io"
+	// PostDeleteFinalizerCleanupStage is the stage of the post-delete finalizer that deletes the hooks again
+	PostDeleteFinalizerCleanupStage = "cleanup"
+)
+
+// propagationPolicyFinalizers maps the resources finalizers to the propagation policy they delete with
+var propagationPolicyFinalizers = map[string]metav1.DeletionPropagation{
+	ResourcesFinalizerName:               metav1.DeletePropagationForeground,
+	ForegroundPropagationPolicyFinalizer: metav1.DeletePropagationForeground,
+	BackgroundPropagationPolicyFinalizer: metav1.DeletePropagationBackground,
+}
+
+// IsFinalizerPresent returns true if the application has the given finalizer
+func (app *Application) IsFinalizerPresent(finalizer string) bool {
+	return slices.Contains(app.Finalizers, finalizer)
+}
+
+// SetCascadedDeletion makes deleting the application delete its resources with the given resources finalizer.
+// Other resources finalizers are removed.
+func (app *Application) SetCascadedDeletion(finalizer string) {
+	app.UnSetCascadedDeletion()
+	app.Finalizers = append(app.Finalizers, finalizer)
+}
+
+// UnSetCascadedDeletion removes all resources finalizers, so deleting the application keeps its resources
+func (app *Application) UnSetCascadedDeletion() {
+	app.Finalizers = slices.DeleteFunc(app.Finalizers, isPropagationPolicyFinalizer)
+}
+
+// CascadedDeletion returns true if deleting the application deletes its resources
+func (app *Application) CascadedDeletion() bool {
+	return slices.ContainsFunc(app.Finalizers, isPropagationPolicyFinalizer)
+}
+
+// GetPropagationPolicy returns the resources finalizer of the application or an empty string if there is none
+func (app *Application) GetPropagationPolicy() string {
+	for _, finalizer := range app.Finalizers {
+		if isPropagationPolicyFinalizer(finalizer) {
+			return finalizer
+		}
+	}
+	return ""
+}
+
+// DeletionPropagation returns the propagation policy the resources of the application are deleted with. It
+// returns false if the resources are kept when the application is deleted.
+func (app *Application) DeletionPropagation() (metav1.DeletionPropagation, bool) {
+	policy, ok := propagationPolicyFinalizers[app.GetPropagationPolicy()]
+	return policy, ok
+}
+
+// SetPostDeleteFinalizer adds the post-delete finalizer for the given stage, or the main post-delete
+// finalizer if stage is empty
+func (app *Application) SetPostDeleteFinalizer(stage string) {
+	finalizer := postDeleteFinalizer(stage)
+	if !app.IsFinalizerPresent(finalizer) {
+		app.Finalizers = append(app.Finalizers, finalizer)
+	}
+}
+
+// UnSetPostDeleteFinalizer removes the post-delete finalizer for the given stage, or the main post-delete
+// finalizer if stage is empty
+func (app *Application) UnSetPostDeleteFinalizer(stage string) {
+	finalizer := postDeleteFinalizer(stage)
+	app.Finalizers = slices.DeleteFunc(app.Finalizers, func(f string) bool { return f == finalizer })
+}
+
+// HasPostDeleteFinalizer returns true if the application has the post-delete finalizer for the given stage,
+// or the main post-delete finalizer if stage is empty
+func (app *Application) HasPostDeleteFinalizer(stage string) bool {
+	return app.IsFinalizerPresent(postDeleteFinalizer(stage))
+}
+
+// IsBeingDeleted returns true if the application was requested to be deleted
+func (app *Application) IsBeingDeleted() bool {
+	return app.DeletionTimestamp != nil
+}
+
+// PendingDeletionFinalizers returns the Argo CD finalizers that still hold back the deletion of the
+// application, or nil if it is not being deleted
+func (app *Application) PendingDeletionFinalizers() []string {
+	if !app.IsBeingDeleted() {
+		return nil
+	}
+	var pending []string
+	for _, finalizer := range app.Finalizers {
+		if isPropagationPolicyFinalizer(finalizer) || IsPostDeleteFinalizer(finalizer) {
+			pending = append(pending, finalizer)
+		}
+	}
+	return pending
+}
+
+// IsWaitingOnDeletion returns true if the application is being deleted and Argo CD still has to delete its
+// resources or run its post-delete hooks
+func (app *Application) IsWaitingOnDeletion() bool {
+	return len(app.PendingDeletionFinalizers()) > 0
+}
+
+func isPropagationPolicyFinalizer(finalizer string) bool {
+	_, ok := propagationPolicyFinalizers[finalizer]
+	return ok
+}
+
+// IsPostDeleteFinalizer returns true if finalizer is the post-delete finalizer or one of its stages, which
+// Argo CD adds and removes itself
+func IsPostDeleteFinalizer(finalizer string) bool {
+	return finalizer == PostDeleteFinalizerName || strings.HasPrefix(finalizer, PostDeleteFinalizerName+"/")
+}
+
+func postDeleteFinalizer(stage string) string {
+	if stage == "" {
+		return PostDeleteFinalizerName
+	}
+	return PostDeleteFinalizerName + "/" + stage
+}
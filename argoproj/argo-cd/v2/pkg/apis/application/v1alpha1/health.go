@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application"
+)
+
+// healthOrder is the order of health statuses from best to worst, as used by Argo CD to aggregate health
+var healthOrder = []HealthStatusCode{
+	HealthStatusHealthy,
+	HealthStatusSuspended,
+	HealthStatusProgressing,
+	HealthStatusMissing,
+	HealthStatusDegraded,
+	HealthStatusUnknown,
+}
+
+// Severity returns the position of the status in Argo CD's health ordering, higher values being worse.
+// As in Argo CD, unrecognized statuses rank like Healthy.
+func (c HealthStatusCode) Severity() int {
+	return max(slices.Index(healthOrder, c), 0)
+}
+
+// IsWorse returns true if c is a worse health status than other
+func (c HealthStatusCode) IsWorse(other HealthStatusCode) bool {
+	return c.Severity() > other.Severity()
+}
+
+// IsBetter returns true if c is a better health status than other
+func (c HealthStatusCode) IsBetter(other HealthStatusCode) bool {
+	return c.Severity() < other.Severity()
+}
+
+// AggregateHealthStatus computes the health of an application from the health of its resources. The result
+// is the worst health of all resources, or Healthy if there are none.
+//
+// Hooks and resources without a health status are skipped, and Missing or Unknown child Applications do not
+// affect the result. If source is ResourceHealthLocationAppTree the resource health is not persisted inline,
+// so resourceHealth is used to look it up; otherwise ResourceStatus.Health is used. resourceHealth may be nil
+// for inline health.
+func AggregateHealthStatus(resources []ResourceStatus, source ResourceHealthLocation, resourceHealth func(ResourceStatus) *HealthStatus) AppHealthStatus {
+	appHealth := HealthStatusHealthy
+	for _, res := range resources {
+		if res.Hook {
+			continue
+		}
+
+		health := res.Health
+		if source == ResourceHealthLocationAppTree {
+			health = nil
+			if resourceHealth != nil {
+				health = resourceHealth(res)
+			}
+		}
+		if health == nil || health.Status == "" {
+			continue
+		}
+
+		// Missing or Unknown health status of child Argo CD app should not affect parent
+		if res.Group == application.Group && res.Kind == application.ApplicationKind &&
+			(health.Status == HealthStatusMissing || health.Status == HealthStatusUnknown) {
+			continue
+		}
+		if health.Status.IsWorse(appHealth) {
+			appHealth = health.Status
+		}
+	}
+	return AppHealthStatus{Status: appHealth}
+}
+
+// AggregateHealthStatus computes the health of the application from its resources, honoring
+// ResourceHealthSource. See AggregateHealthStatus for details.
+func (status *ApplicationStatus) AggregateHealthStatus(resourceHealth func(ResourceStatus) *HealthStatus) AppHealthStatus {
+	return AggregateHealthStatus(status.Resources, status.ResourceHealthSource, resourceHealth)
+}
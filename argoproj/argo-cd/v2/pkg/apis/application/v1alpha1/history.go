@@ -0,0 +1,110 @@
+package v1alpha1
+
+// DefaultRevisionHistoryLimit is the number of history entries kept if RevisionHistoryLimit is not set
+const DefaultRevisionHistoryLimit = 10
+
+// GetRevisionHistoryLimit returns the number of history entries to keep
+func (spec *ApplicationSpec) GetRevisionHistoryLimit() int {
+	if spec.RevisionHistoryLimit != nil {
+		return int(*spec.RevisionHistoryLimit)
+	}
+	return DefaultRevisionHistoryLimit
+}
+
+// FindByID returns the history entry with the given ID or nil if there is none
+func (histories RevisionHistories) FindByID(id int64) *RevisionHistory {
+	for i := range histories {
+		if histories[i].ID == id {
+			return &histories[i]
+		}
+	}
+	return nil
+}
+
+// FindByRevision returns the newest history entry deployed from the given revision, matching Revision as well
+// as any of the Revisions of a multi-source entry, or nil if there is none
+func (histories RevisionHistories) FindByRevision(revision string) *RevisionHistory {
+	for i := len(histories) - 1; i >= 0; i-- {
+		if histories[i].Revision == revision {
+			return &histories[i]
+		}
+		for _, r := range histories[i].Revisions {
+			if r == revision {
+				return &histories[i]
+			}
+		}
+	}
+	return nil
+}
+
+// LastRevisionHistory returns the newest history entry or nil if the history is empty
+func (histories RevisionHistories) LastRevisionHistory() *RevisionHistory {
+	if len(histories) == 0 {
+		return nil
+	}
+	return &histories[len(histories)-1]
+}
+
+// NextID returns the ID of the next history entry
+func (histories RevisionHistories) NextID() int64 {
+	next := int64(0)
+	for _, h := range histories {
+		next = max(next, h.ID+1)
+	}
+	return next
+}
+
+// Trunc returns the newest n entries
+func (histories RevisionHistories) Trunc(n int) RevisionHistories {
+	if i := len(histories) - max(n, 0); i > 0 {
+		return histories[i:]
+	}
+	return histories
+}
+
+// IsDeployable returns true if the entry recorded the source(s) it was deployed from, which is required to
+// roll back to it
+func (h *RevisionHistory) IsDeployable() bool {
+	return !h.Source.IsZero() || !h.Sources.IsZero()
+}
+
+// AppendHistory assigns the next ID to entry, appends it to the history and returns the ID
+func (status *ApplicationStatus) AppendHistory(entry RevisionHistory) int64 {
+	entry.ID = status.History.NextID()
+	status.History = append(status.History, entry)
+	return entry.ID
+}
+
+// TrimHistory keeps only the newest limit history entries
+func (status *ApplicationStatus) TrimHistory(limit int) {
+	status.History = status.History.Trunc(limit)
+}
+
+// FindHistoryByID returns the history entry with the given ID or nil if there is none
+func (status *ApplicationStatus) FindHistoryByID(id int64) *RevisionHistory {
+	return status.History.FindByID(id)
+}
+
+// FindHistoryByRevision returns the newest history entry deployed from the given revision or nil if there is none
+func (status *ApplicationStatus) FindHistoryByRevision(revision string) *RevisionHistory {
+	return status.History.FindByRevision(revision)
+}
+
+// PreviousDeployableHistory returns the newest deployable entry before the current deployment, which is the
+// entry a rollback without ID goes back to, or nil if there is none
+func (status *ApplicationStatus) PreviousDeployableHistory() *RevisionHistory {
+	for i := len(status.History) - 2; i >= 0; i-- {
+		if status.History[i].IsDeployable() {
+			return &status.History[i]
+		}
+	}
+	return nil
+}
+
+// RecordHistory appends entry to the history of the application and trims the history to the limit of the
+// spec. It returns the ID of the new entry.
+func (app *Application) RecordHistory(entry RevisionHistory) int64 {
+	id := app.Status.AppendHistory(entry)
+	app.Status.TrimHistory(app.Spec.GetRevisionHistoryLimit())
+	return id
+}
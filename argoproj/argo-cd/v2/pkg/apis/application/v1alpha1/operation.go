@@ -0,0 +1,180 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operationTransitions holds the phases an operation may move to from each phase. A new operation starts
+// Running, and a completed operation starts Running again when it is retried or replaced.
+var operationTransitions = map[OperationPhase][]OperationPhase{
+	"":                   {OperationRunning},
+	OperationRunning:     {OperationTerminating, OperationFailed, OperationError, OperationSucceeded},
+	OperationTerminating: {OperationFailed, OperationError},
+	OperationFailed:      {OperationRunning},
+	OperationError:       {OperationRunning},
+	OperationSucceeded:   {OperationRunning},
+}
+
+// IsValid returns true if p is one of the known operation phases
+func (p OperationPhase) IsValid() bool {
+	_, ok := operationTransitions[p]
+	return ok && p != ""
+}
+
+// Completed returns true if the operation finished, successfully or not
+func (p OperationPhase) Completed() bool {
+	switch p {
+	case OperationFailed, OperationError, OperationSucceeded:
+		return true
+	}
+	return false
+}
+
+// Running returns true if the operation is running
+func (p OperationPhase) Running() bool {
+	return p == OperationRunning
+}
+
+// Successful returns true if the operation succeeded
+func (p OperationPhase) Successful() bool {
+	return p == OperationSucceeded
+}
+
+// Failed returns true if the operation failed or errored
+func (p OperationPhase) Failed() bool {
+	return p == OperationFailed || p == OperationError
+}
+
+// CanTransitionTo returns true if an operation may move from p to next. Staying in the same phase is allowed.
+func (p OperationPhase) CanTransitionTo(next OperationPhase) bool {
+	if p == next {
+		return next.IsValid()
+	}
+	for _, allowed := range operationTransitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an error if an operation may not move from phase from to phase to
+func ValidateTransition(from, to OperationPhase) error {
+	if !to.IsValid() {
+		return fmt.Errorf("unknown operation phase %q", to)
+	}
+	if from != "" && !from.IsValid() {
+		return fmt.Errorf("unknown operation phase %q", from)
+	}
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("invalid operation phase transition from %q to %q", from, to)
+	}
+	return nil
+}
+
+// IsCompleted returns true if the operation finished, successfully or not
+func (state *OperationState) IsCompleted() bool {
+	return state != nil && state.Phase.Completed()
+}
+
+// Successful returns true if the operation succeeded
+func (state *OperationState) Successful() bool {
+	return state != nil && state.Phase.Successful()
+}
+
+// IsValid returns true if c is one of the known result codes
+func (c ResultCode) IsValid() bool {
+	switch c {
+	case ResultCodeSynced, ResultCodeSyncFailed, ResultCodePruned, ResultCodePruneSkipped, ResultCodePruneConfirm:
+		return true
+	}
+	return false
+}
+
+// IsHook returns true if the result belongs to a hook
+func (r *ResourceResult) IsHook() bool {
+	return r.HookType != ""
+}
+
+// Failed returns true if the resource could not be applied or, for hooks, if the hook failed
+func (r *ResourceResult) Failed() bool {
+	if r.IsHook() {
+		return r.HookPhase.Failed()
+	}
+	return r.Status == ResultCodeSyncFailed
+}
+
+// Filter returns the results for which predicate returns true
+func (rr ResourceResults) Filter(predicate func(r *ResourceResult) bool) ResourceResults {
+	var filtered ResourceResults
+	for _, r := range rr {
+		if predicate(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// BySyncPhase returns the results acquired in the given sync phase
+func (rr ResourceResults) BySyncPhase(phase SyncPhase) ResourceResults {
+	return rr.Filter(func(r *ResourceResult) bool { return r.SyncPhase == phase })
+}
+
+// ByHookPhase returns the hook results in the given operation phase
+func (rr ResourceResults) ByHookPhase(phase OperationPhase) ResourceResults {
+	return rr.Filter(func(r *ResourceResult) bool { return r.IsHook() && r.HookPhase == phase })
+}
+
+// ByStatus returns the results with the given result code
+func (rr ResourceResults) ByStatus(code ResultCode) ResourceResults {
+	return rr.Filter(func(r *ResourceResult) bool { return r.Status == code })
+}
+
+// Hooks returns the results of hooks
+func (rr ResourceResults) Hooks() ResourceResults {
+	return rr.Filter(func(r *ResourceResult) bool { return r.IsHook() })
+}
+
+// Resources returns the results of resources that are not hooks
+func (rr ResourceResults) Resources() ResourceResults {
+	return rr.Filter(func(r *ResourceResult) bool { return !r.IsHook() })
+}
+
+// Failures returns the results of resources and hooks that failed
+func (rr ResourceResults) Failures() ResourceResults {
+	return rr.Filter(func(r *ResourceResult) bool { return r.Failed() })
+}
+
+// PruningRequired returns the number of resources that were not pruned because pruning was disabled
+func (rr ResourceResults) PruningRequired() int {
+	return len(rr.ByStatus(ResultCodePruneSkipped))
+}
+
+// FailureSummary describes the failed results in one line, or returns an empty string if nothing failed
+func (rr ResourceResults) FailureSummary() string {
+	failures := rr.Failures()
+	if len(failures) == 0 {
+		return ""
+	}
+	messages := make([]string, 0, len(failures))
+	for _, r := range failures {
+		name := r.Name
+		if r.Namespace != "" {
+			name = r.Namespace + "/" + name
+		}
+		kind := r.Kind
+		if r.Group != "" {
+			kind = r.Group + "/" + kind
+		}
+		message := fmt.Sprintf("%s %s", kind, name)
+		if r.IsHook() {
+			message += fmt.Sprintf(" (%s hook)", r.HookType)
+		}
+		if r.Message != "" {
+			message += ": " + r.Message
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Sprintf("%d of %d resources failed: %s", len(failures), len(rr), strings.Join(messages, "; "))
+}
@@ -0,0 +1,28 @@
+package v1alpha1
+
+const (
+	// AnnotationKeyRefresh requests a refresh of the application, its value is the RefreshType. The
+	// controller removes it once the refresh is done.
+	AnnotationKeyRefresh = "argocd.argoproj.io/refresh"
+	// AnnotationKeyHydrate requests a hydration of the application, its value is the HydrateType. The
+	// controller removes it once the hydration is done.
+	AnnotationKeyHydrate = "argocd.argoproj.io/hydrate"
+)
+
+// IsRefreshRequested returns true if a refresh of the application is pending, and the type of the refresh.
+// As in Argo CD, any value other than hard requests a normal refresh.
+func (app *Application) IsRefreshRequested() (RefreshType, bool) {
+	value, ok := app.GetAnnotations()[AnnotationKeyRefresh]
+	if !ok {
+		return RefreshTypeNormal, false
+	}
+	if value == string(RefreshTypeHard) {
+		return RefreshTypeHard, true
+	}
+	return RefreshTypeNormal, true
+}
+
+// IsHydrateRequested returns true if a hydration of the application is pending
+func (app *Application) IsHydrateRequested() bool {
+	return app.GetAnnotations()[AnnotationKeyHydrate] == string(HydrateTypeNormal)
+}
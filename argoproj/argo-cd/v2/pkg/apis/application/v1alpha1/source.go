@@ -0,0 +1,60 @@
+package v1alpha1
+
+import (
+	"reflect"
+
+	"sigs.k8s.io/yaml"
+)
+
+// HasMultipleSources returns true if the application uses the Sources field instead of Source
+func (spec *ApplicationSpec) HasMultipleSources() bool {
+	return spec.SourceHydrator == nil && len(spec.Sources) > 0
+}
+
+// GetSources returns the sources of the application, wrapping a single Source in a list
+func (spec *ApplicationSpec) GetSources() ApplicationSources {
+	if spec.HasMultipleSources() {
+		return spec.Sources
+	}
+	if spec.Source != nil {
+		return ApplicationSources{*spec.Source}
+	}
+	return ApplicationSources{}
+}
+
+// IsZero returns true if the source is nil or has no field set
+func (source *ApplicationSource) IsZero() bool {
+	return source == nil || reflect.ValueOf(*source).IsZero()
+}
+
+// IsZero returns true if there are no sources or all of them are zero
+func (sources ApplicationSources) IsZero() bool {
+	for i := range sources {
+		if !sources[i].IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// ValuesYAML returns the Helm values as YAML. As in Argo CD, ValuesObject takes precedence over Values.
+func (h *ApplicationSourceHelm) ValuesYAML() []byte {
+	if h.ValuesObject == nil || len(h.ValuesObject.Raw) == 0 {
+		return []byte(h.Values)
+	}
+	b, err := yaml.JSONToYAML(h.ValuesObject.Raw)
+	if err != nil {
+		// the raw extension holds valid JSON, so this should never happen
+		return []byte(h.Values)
+	}
+	return b
+}
+
+// ValuesIsEmpty returns true if the Helm values set nothing, such as empty Values YAML or an empty ValuesObject
+func (h *ApplicationSourceHelm) ValuesIsEmpty() bool {
+	var values map[string]any
+	if err := yaml.Unmarshal(h.ValuesYAML(), &values); err != nil {
+		return false
+	}
+	return len(values) == 0
+}
@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Defaults of the retry backoff of automated and manual syncs, as applied by the controller
+const (
+	DefaultSyncRetryDuration    = 5 * time.Second
+	DefaultSyncRetryMaxDuration = 3 * time.Minute
+	DefaultSyncRetryFactor      = int64(2)
+)
+
+// SyncStatusCodes holds all known sync status codes
+var SyncStatusCodes = []SyncStatusCode{SyncStatusCodeSynced, SyncStatusCodeOutOfSync, SyncStatusCodeUnknown}
+
+// IsValid returns true if c is one of the known sync status codes
+func (c SyncStatusCode) IsValid() bool {
+	switch c {
+	case SyncStatusCodeSynced, SyncStatusCodeOutOfSync, SyncStatusCodeUnknown:
+		return true
+	}
+	return false
+}
+
+// IsSynced returns true if desired and live states match
+func (c SyncStatusCode) IsSynced() bool {
+	return c == SyncStatusCodeSynced
+}
+
+// IsOutOfSync returns true if desired and live states differ
+func (c SyncStatusCode) IsOutOfSync() bool {
+	return c == SyncStatusCodeOutOfSync
+}
+
+// IsUnknown returns true if the sync status could not be determined. An empty status is considered unknown.
+func (c SyncStatusCode) IsUnknown() bool {
+	return c == SyncStatusCodeUnknown || c == ""
+}
+
+// HookTypes holds all known hook types
+var HookTypes = []HookType{HookTypePreSync, HookTypeSync, HookTypePostSync, HookTypeSkip, HookTypeSyncFail, HookTypePostDelete}
+
+// SyncPhases holds the sync phases in the order Argo CD runs them. PostDelete hooks only run once the
+// application is deleted.
+var SyncPhases = []SyncPhase{SyncPhasePreSync, SyncPhaseSync, SyncPhasePostSync, SyncPhaseSyncFail, SyncPhasePostDelete}
+
+// IsValid returns true if t is one of the known hook types
+func (t HookType) IsValid() bool {
+	return slices.Contains(HookTypes, t)
+}
+
+// SyncPhase returns the sync phase hooks of this type run in. Skip and unknown hook types have no phase.
+func (t HookType) SyncPhase() (SyncPhase, bool) {
+	if t == HookTypeSkip || !t.IsValid() {
+		return "", false
+	}
+	return SyncPhase(t), true
+}
+
+// Order returns the position of the phase in SyncPhases, or -1 if it is unknown
+func (p SyncPhase) Order() int {
+	return slices.Index(SyncPhases, p)
+}
+
+// IsAutomatedSyncEnabled returns true if automated sync is configured and not explicitly disabled
+func (p *SyncPolicy) IsAutomatedSyncEnabled() bool {
+	return p != nil && p.Automated != nil && (p.Automated.Enabled == nil || *p.Automated.Enabled)
+}
+
+// IsDefault returns true if the backoff is unset or only sets the values the controller uses by default
+func (b *Backoff) IsDefault() bool {
+	if b == nil {
+		return true
+	}
+	if b.Duration != "" {
+		if d, err := parseBackoffDuration(b.Duration); err != nil || d != DefaultSyncRetryDuration {
+			return false
+		}
+	}
+	if b.MaxDuration != "" {
+		if d, err := parseBackoffDuration(b.MaxDuration); err != nil || d != DefaultSyncRetryMaxDuration {
+			return false
+		}
+	}
+	return b.Factor == nil || *b.Factor == DefaultSyncRetryFactor
+}
+
+// parseBackoffDuration parses a backoff duration, which like in Argo CD may also be a plain number of seconds
+func parseBackoffDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,103 @@
+// Package annotations parses and validates the resource annotations Argo CD reads to control how a resource
+// is synced, compared and displayed.
+package annotations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// AnnotationSyncWave holds the sync wave of a resource
+	AnnotationSyncWave = "argocd.argoproj.io/sync-wave"
+	// AnnotationHook holds the comma separated hook types of a resource
+	AnnotationHook = "argocd.argoproj.io/hook"
+	// AnnotationHookDeletePolicy holds the comma separated delete policies of a hook
+	AnnotationHookDeletePolicy = "argocd.argoproj.io/hook-delete-policy"
+	// AnnotationSyncOptions holds the comma separated sync options of a resource
+	AnnotationSyncOptions = "argocd.argoproj.io/sync-options"
+	// AnnotationCompareOptions holds the comma separated compare options of a resource
+	AnnotationCompareOptions = "argocd.argoproj.io/compare-options"
+	// AnnotationManifestGeneratePaths holds the semicolon separated paths whose changes require an application
+	// to regenerate its manifests. It is set on Applications.
+	AnnotationManifestGeneratePaths = "argocd.argoproj.io/manifest-generate-paths"
+	// AnnotationLinkPrefix is the prefix of annotations holding external links, the rest of the key is the title
+	AnnotationLinkPrefix = "link.argocd.argoproj.io/"
+
+	// AnnotationHelmHook holds the comma separated Helm hooks of a resource
+	AnnotationHelmHook = "helm.sh/hook"
+	// AnnotationHelmHookWeight holds the Helm hook weight, which is used as wave if there is no sync wave
+	AnnotationHelmHookWeight = "helm.sh/hook-weight"
+	// AnnotationHelmHookDeletePolicy holds the comma separated Helm hook delete policies
+	AnnotationHelmHookDeletePolicy = "helm.sh/hook-delete-policy"
+)
+
+// Error is an invalid annotation value
+type Error struct {
+	// Key is the annotation key
+	Key string
+	// Value is the annotation value
+	Value string
+	// Err describes why the value is invalid
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid value %q of annotation %s: %v", e.Value, e.Key, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(key, value string, format string, args ...any) *Error {
+	return &Error{Key: key, Value: value, Err: fmt.Errorf(format, args...)}
+}
+
+// Validate parses all annotations of obj that Argo CD reads and returns an error for each invalid value,
+// ordered by annotation key. List annotations get an error for each invalid item.
+func Validate(obj *unstructured.Unstructured) []error {
+	var errs []error
+	collect := func(err error) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = append(errs, joined.Unwrap()...)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	_, err := SyncWave(obj)
+	collect(err)
+	_, err = HookTypes(obj)
+	collect(err)
+	_, err = HookDeletePolicies(obj)
+	collect(err)
+	_, err = SyncOptions(obj)
+	collect(err)
+	_, err = CompareOptionsOf(obj)
+	collect(err)
+	_, err = ExternalLinks(obj)
+	collect(err)
+	sort.SliceStable(errs, func(i, j int) bool { return errorKey(errs[i]) < errorKey(errs[j]) })
+	return errs
+}
+
+func errorKey(err error) string {
+	if e, ok := err.(*Error); ok {
+		return e.Key
+	}
+	return ""
+}
+
+// splitList splits a separated list, trimming whitespace and dropping empty items
+func splitList(value, separator string) []string {
+	var items []string
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
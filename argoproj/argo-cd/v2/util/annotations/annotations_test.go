@@ -0,0 +1,95 @@
+package annotations
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidate(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAnnotations(map[string]string{
+		AnnotationSyncWave:                  "first",
+		AnnotationHook:                      "PreSync,Before,After",
+		AnnotationSyncOptions:               "Prune=false,Prune=never,Unknown=true",
+		AnnotationCompareOptions:            "IgnoreExtraneous",
+		AnnotationLinkPrefix + "docs":       "ftp://example.com",
+		AnnotationLinkPrefix + "dashboard":  "example.com",
+		AnnotationLinkPrefix + "repository": "https://example.com",
+	})
+	errs := Validate(obj)
+	var keys []string
+	for _, err := range errs {
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("expected an annotation error, got %v", err)
+		}
+		keys = append(keys, e.Key)
+	}
+	expected := []string{
+		AnnotationHook, AnnotationHook,
+		AnnotationSyncOptions, AnnotationSyncOptions,
+		AnnotationSyncWave,
+		AnnotationLinkPrefix + "dashboard", AnnotationLinkPrefix + "docs",
+	}
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("expected error %d for %s, got %s", i, expected[i], keys[i])
+		}
+	}
+
+	types, err := HookTypes(obj)
+	if len(types) != 1 || err == nil {
+		t.Errorf("expected the valid hook type and an error, got %v, %v", types, err)
+	}
+}
+
+func TestHookTypes(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		expected    []v1alpha1.HookType
+		err         bool
+	}{
+		{
+			name:        "argo hook",
+			annotations: map[string]string{AnnotationHook: "PreSync,PostSync", AnnotationHelmHook: "post-delete"},
+			expected:    []v1alpha1.HookType{v1alpha1.HookTypePreSync, v1alpha1.HookTypePostSync},
+		},
+		{
+			name:        "helm hook",
+			annotations: map[string]string{AnnotationHelmHook: "pre-install,test"},
+			expected:    []v1alpha1.HookType{v1alpha1.HookTypePreSync},
+		},
+		{
+			name:        "invalid argo hook types fall back to helm hook",
+			annotations: map[string]string{AnnotationHook: "Before", AnnotationHelmHook: "post-install"},
+			expected:    []v1alpha1.HookType{v1alpha1.HookTypePostSync},
+			err:         true,
+		},
+		{
+			name:        "partly invalid argo hook types take precedence",
+			annotations: map[string]string{AnnotationHook: "Before,Sync", AnnotationHelmHook: "post-install"},
+			expected:    []v1alpha1.HookType{v1alpha1.HookTypeSync},
+			err:         true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAnnotations(tc.annotations)
+			types, err := HookTypes(obj)
+			if (err != nil) != tc.err {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !slices.Equal(types, tc.expected) {
+				t.Errorf("unexpected hook types %v", types)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package annotations
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// CompareOptionIgnoreExtraneous excludes the resource from the sync status of the application if it is
+	// not part of the desired manifests
+	CompareOptionIgnoreExtraneous = "IgnoreExtraneous"
+	// CompareOptionServerSideDiff enables or disables server-side diff for the resource
+	CompareOptionServerSideDiff = "ServerSideDiff"
+	// CompareOptionIncludeMutationWebhook includes changes of mutation webhooks in server-side diffs
+	CompareOptionIncludeMutationWebhook = "IncludeMutationWebhook"
+)
+
+// CompareOptions are the compare options of a resource
+type CompareOptions struct {
+	// IgnoreExtraneous is set by IgnoreExtraneous
+	IgnoreExtraneous bool
+	// ServerSideDiff is set by ServerSideDiff=true or ServerSideDiff=false, nil if not set
+	ServerSideDiff *bool
+	// IncludeMutationWebhook is set by IncludeMutationWebhook=true
+	IncludeMutationWebhook bool
+}
+
+// CompareOptionsOf returns the compare options of obj. Unknown options and values are an error, joined with one
+// error per invalid option, and are left out of the result.
+func CompareOptionsOf(obj *unstructured.Unstructured) (CompareOptions, error) {
+	options := CompareOptions{}
+	value, ok := obj.GetAnnotations()[AnnotationCompareOptions]
+	if !ok {
+		return options, nil
+	}
+	var errs []error
+	for _, option := range splitList(value, ",") {
+		key, optionValue, hasValue := strings.Cut(option, "=")
+		switch key {
+		case CompareOptionIgnoreExtraneous:
+			if hasValue {
+				errs = append(errs, newError(AnnotationCompareOptions, value, "compare option %s does not take a value", key))
+				continue
+			}
+			options.IgnoreExtraneous = true
+		case CompareOptionServerSideDiff, CompareOptionIncludeMutationWebhook:
+			enabled, parseErr := strconv.ParseBool(optionValue)
+			if parseErr != nil {
+				errs = append(errs, newError(AnnotationCompareOptions, value, "compare option %s must be true or false", key))
+				continue
+			}
+			if key == CompareOptionServerSideDiff {
+				options.ServerSideDiff = &enabled
+			} else {
+				options.IncludeMutationWebhook = enabled
+			}
+		default:
+			errs = append(errs, newError(AnnotationCompareOptions, value, "unknown compare option %q", key))
+		}
+	}
+	return options, errors.Join(errs...)
+}
@@ -0,0 +1,46 @@
+package annotations
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ExternalLink is a link shown for a resource in the Argo CD UI
+type ExternalLink struct {
+	// Title is the part of the annotation key after AnnotationLinkPrefix
+	Title string
+	// URL is the annotation value
+	URL string
+}
+
+// ExternalLinks returns the external links of obj ordered by title. Links that are not absolute http or https
+// URLs are an error, joined with one error per invalid link ordered by key, and are left out of the result.
+func ExternalLinks(obj *unstructured.Unstructured) ([]ExternalLink, error) {
+	var links []ExternalLink
+	var errs []error
+	for key, value := range obj.GetAnnotations() {
+		title, ok := strings.CutPrefix(key, AnnotationLinkPrefix)
+		if !ok {
+			continue
+		}
+		u, parseErr := url.Parse(value)
+		if parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, newError(key, value, "must be an absolute http or https URL"))
+			continue
+		}
+		links = append(links, ExternalLink{Title: title, URL: value})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Title < links[j].Title })
+	sort.Slice(errs, func(i, j int) bool { return errorKey(errs[i]) < errorKey(errs[j]) })
+	return links, errors.Join(errs...)
+}
+
+// ManifestGeneratePaths returns the paths of the manifest-generate-paths annotation. Relative paths are
+// relative to the path of the application source, absolute paths to the repository root.
+func ManifestGeneratePaths(obj *unstructured.Unstructured) []string {
+	return splitList(obj.GetAnnotations()[AnnotationManifestGeneratePaths], ";")
+}
@@ -0,0 +1,163 @@
+package annotations
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// HookDeletePolicy is the policy that controls when a hook is deleted
+type HookDeletePolicy string
+
+const (
+	// HookDeletePolicyHookSucceeded deletes the hook after it succeeded
+	HookDeletePolicyHookSucceeded HookDeletePolicy = "HookSucceeded"
+	// HookDeletePolicyHookFailed deletes the hook after it failed
+	HookDeletePolicyHookFailed HookDeletePolicy = "HookFailed"
+	// HookDeletePolicyBeforeHookCreation deletes the previous hook before the hook is created again. It is the
+	// default if no policy is set.
+	HookDeletePolicyBeforeHookCreation HookDeletePolicy = "BeforeHookCreation"
+)
+
+// HelmHookCRDInstall is the Helm hook of CRDs. Argo CD applies them as regular resources, not as hooks.
+const HelmHookCRDInstall = "crd-install"
+
+// helmHookTypes maps the Helm hooks Argo CD supports to hook types. Other Helm hooks are ignored.
+var helmHookTypes = map[string]v1alpha1.HookType{
+	HelmHookCRDInstall: v1alpha1.HookTypePreSync,
+	"pre-install":      v1alpha1.HookTypePreSync,
+	"pre-upgrade":      v1alpha1.HookTypePreSync,
+	"post-install":     v1alpha1.HookTypePostSync,
+	"post-upgrade":     v1alpha1.HookTypePostSync,
+	"post-delete":      v1alpha1.HookTypePostDelete,
+}
+
+// helmHookDeletePolicies maps Helm hook delete policies to hook delete policies
+var helmHookDeletePolicies = map[string]HookDeletePolicy{
+	"hook-succeeded":       HookDeletePolicyHookSucceeded,
+	"hook-failed":          HookDeletePolicyHookFailed,
+	"before-hook-creation": HookDeletePolicyBeforeHookCreation,
+}
+
+// SyncWave returns the sync wave of obj, 0 if it has none. As in Argo CD, the Helm hook weight is used if
+// there is no sync wave annotation.
+func SyncWave(obj *unstructured.Unstructured) (int, error) {
+	for _, key := range []string{AnnotationSyncWave, AnnotationHelmHookWeight} {
+		value, ok := obj.GetAnnotations()[key]
+		if !ok {
+			continue
+		}
+		wave, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, newError(key, value, "must be an integer")
+		}
+		return wave, nil
+	}
+	return 0, nil
+}
+
+// HookTypes returns the hook types of obj. The Argo CD hook annotation takes precedence over the Helm one,
+// unless none of its types is valid. Unknown Argo CD hook types are an error, joined with one error per
+// unknown type, and are left out of the result. Helm hooks Argo CD does not support are ignored.
+func HookTypes(obj *unstructured.Unstructured) ([]v1alpha1.HookType, error) {
+	var types []v1alpha1.HookType
+	var errs []error
+	if value, ok := obj.GetAnnotations()[AnnotationHook]; ok {
+		for _, t := range splitList(value, ",") {
+			hookType := v1alpha1.HookType(t)
+			if !hookType.IsValid() {
+				errs = append(errs, newError(AnnotationHook, value, "unknown hook type %q, must be one of %v", t, v1alpha1.HookTypes))
+				continue
+			}
+			types = append(types, hookType)
+		}
+		if len(types) > 0 {
+			return types, errors.Join(errs...)
+		}
+	}
+	for _, t := range splitList(obj.GetAnnotations()[AnnotationHelmHook], ",") {
+		if hookType, ok := helmHookTypes[t]; ok {
+			types = append(types, hookType)
+		}
+	}
+	return types, errors.Join(errs...)
+}
+
+// HookDeletePolicies returns the delete policies of a hook, BeforeHookCreation if it has none. The Argo CD
+// annotation takes precedence over the Helm one. Unknown policies are an error, joined with one error per
+// unknown policy, and are left out of the result.
+func HookDeletePolicies(obj *unstructured.Unstructured) ([]HookDeletePolicy, error) {
+	var policies []HookDeletePolicy
+	var errs []error
+	if value, ok := obj.GetAnnotations()[AnnotationHookDeletePolicy]; ok {
+		for _, p := range splitList(value, ",") {
+			switch policy := HookDeletePolicy(p); policy {
+			case HookDeletePolicyHookSucceeded, HookDeletePolicyHookFailed, HookDeletePolicyBeforeHookCreation:
+				policies = append(policies, policy)
+			default:
+				errs = append(errs, newError(AnnotationHookDeletePolicy, value, "unknown hook delete policy %q", p))
+			}
+		}
+	} else if value, ok := obj.GetAnnotations()[AnnotationHelmHookDeletePolicy]; ok {
+		for _, p := range splitList(value, ",") {
+			policy, ok := helmHookDeletePolicies[p]
+			if !ok {
+				errs = append(errs, newError(AnnotationHelmHookDeletePolicy, value, "unknown hook delete policy %q", p))
+				continue
+			}
+			policies = append(policies, policy)
+		}
+	}
+	if len(policies) == 0 {
+		policies = []HookDeletePolicy{HookDeletePolicyBeforeHookCreation}
+	}
+	return policies, errors.Join(errs...)
+}
+
+// syncOptionValues holds the resource level sync options and their accepted values
+var syncOptionValues = map[string][]string{
+	"Prune":                       {"false", "confirm"},
+	"Delete":                      {"false", "confirm"},
+	"Validate":                    {"false", "true"},
+	"SkipDryRunOnMissingResource": {"true"},
+	"Replace":                     {"true", "false"},
+	"ServerSideApply":             {"true", "false"},
+	"Force":                       {"true"},
+	"PruneLast":                   {"true"},
+	"ClientSideApplyMigration":    {"true", "false"},
+}
+
+// SyncOptions returns the sync options of obj. Unknown options and values are an error, joined with one error
+// per invalid option, and are left out of the result.
+func SyncOptions(obj *unstructured.Unstructured) (v1alpha1.SyncOptions, error) {
+	value, ok := obj.GetAnnotations()[AnnotationSyncOptions]
+	if !ok {
+		return nil, nil
+	}
+	var options v1alpha1.SyncOptions
+	var errs []error
+	for _, option := range splitList(value, ",") {
+		key, optionValue, _ := strings.Cut(option, "=")
+		accepted, known := syncOptionValues[key]
+		switch {
+		case !known:
+			errs = append(errs, newError(AnnotationSyncOptions, value, "unknown sync option %q", key))
+		case !slices.Contains(accepted, optionValue):
+			errs = append(errs, newError(AnnotationSyncOptions, value, "invalid value %q of sync option %s, must be one of %v", optionValue, key, accepted))
+		default:
+			options = options.AddOption(option)
+		}
+	}
+	return options, errors.Join(errs...)
+}
+
+// HasSyncOption returns true if obj has the given sync option, such as Prune=false. Invalid options are
+// ignored.
+func HasSyncOption(obj *unstructured.Unstructured, option string) bool {
+	options, _ := SyncOptions(obj)
+	return options.HasOption(option)
+}
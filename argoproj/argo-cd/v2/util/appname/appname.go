@@ -0,0 +1,126 @@
+// Package appname handles the names of Applications that may live outside the Argo CD control plane
+// namespace, known as apps in any namespace.
+package appname
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const (
+	// qualifiedSeparator separates namespace and name in qualified names
+	qualifiedSeparator = "/"
+	// instanceSeparator separates namespace and name in instance names, which must be valid label values
+	instanceSeparator = "_"
+	// defaultProject is the project of applications that do not set one
+	defaultProject = "default"
+)
+
+// QualifiedName is the namespace and name of an Application
+type QualifiedName struct {
+	Namespace string
+	Name      string
+}
+
+// FromApplication returns the qualified name of app
+func FromApplication(app *v1alpha1.Application) QualifiedName {
+	return QualifiedName{Namespace: app.Namespace, Name: app.Name}
+}
+
+// Parse parses a reference to an application in the form namespace/name, or namespace_name as used in
+// tracking metadata. A plain name refers to an application in defaultNamespace. Use tracking.ParseInstanceName
+// for names read from tracking metadata, which only accepts namespace_name.
+func Parse(ref, defaultNamespace string) (QualifiedName, error) {
+	for _, separator := range []string{qualifiedSeparator, instanceSeparator} {
+		namespace, name, ok := strings.Cut(ref, separator)
+		if !ok {
+			continue
+		}
+		if namespace == "" || name == "" || strings.ContainsAny(name, qualifiedSeparator+instanceSeparator) {
+			return QualifiedName{}, fmt.Errorf("invalid application reference %q, expected namespace/name", ref)
+		}
+		return QualifiedName{Namespace: namespace, Name: name}, nil
+	}
+	if ref == "" {
+		return QualifiedName{}, fmt.Errorf("application reference is empty")
+	}
+	return QualifiedName{Namespace: defaultNamespace, Name: ref}, nil
+}
+
+// String formats the name as namespace/name, or just the name if the namespace is empty
+func (n QualifiedName) String() string {
+	if n.Namespace == "" {
+		return n.Name
+	}
+	return n.Namespace + qualifiedSeparator + n.Name
+}
+
+// InstanceName returns the name Argo CD uses in tracking metadata: the plain name for applications in the
+// control plane namespace, namespace_name for all others
+func (n QualifiedName) InstanceName(controlPlaneNamespace string) string {
+	if n.Namespace == "" || n.Namespace == controlPlaneNamespace {
+		return n.Name
+	}
+	return n.Namespace + instanceSeparator + n.Name
+}
+
+// RBACName returns the object of the application in RBAC policies: project/name for applications in the
+// control plane namespace, project/namespace/name for all others
+func (n QualifiedName) RBACName(project, controlPlaneNamespace string) string {
+	if project == "" {
+		project = defaultProject
+	}
+	if controlPlaneNamespace != "" && n.Namespace != "" && n.Namespace != controlPlaneNamespace {
+		return project + qualifiedSeparator + n.Namespace + qualifiedSeparator + n.Name
+	}
+	return project + qualifiedSeparator + n.Name
+}
+
+// RBACName returns the RBAC object of app, see QualifiedName.RBACName
+func RBACName(app *v1alpha1.Application, controlPlaneNamespace string) string {
+	return FromApplication(app).RBACName(app.Spec.Project, ControlPlaneNamespace(app, controlPlaneNamespace))
+}
+
+// ControlPlaneNamespace returns the namespace of the controller that manages app, as reported in
+// Status.ControllerNamespace, or fallback if the controller did not report it yet
+func ControlPlaneNamespace(app *v1alpha1.Application, fallback string) string {
+	if app.Status.ControllerNamespace != "" {
+		return app.Status.ControllerNamespace
+	}
+	return fallback
+}
+
+// IsNamespaceAllowed returns true if app may live in its namespace: applications in the control plane
+// namespace always may, all others only if their namespace matches one of the SourceNamespaces globs of the
+// project
+func IsNamespaceAllowed(app *v1alpha1.Application, project *v1alpha1.AppProject, controlPlaneNamespace string) (bool, error) {
+	namespace := app.Namespace
+	if namespace == "" || namespace == ControlPlaneNamespace(app, controlPlaneNamespace) {
+		return true, nil
+	}
+	for _, pattern := range project.Spec.SourceNamespaces {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("project %s: invalid source namespace %q: %w", project.Name, pattern, err)
+		}
+		if g.Match(namespace) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ValidateNamespace returns an error if app may not live in its namespace, see IsNamespaceAllowed
+func ValidateNamespace(app *v1alpha1.Application, project *v1alpha1.AppProject, controlPlaneNamespace string) error {
+	allowed, err := IsNamespaceAllowed(app, project, controlPlaneNamespace)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("application %s is not allowed in namespace %s by the source namespaces of project %s", FromApplication(app), app.Namespace, project.Name)
+	}
+	return nil
+}
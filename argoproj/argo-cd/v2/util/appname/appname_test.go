@@ -0,0 +1,104 @@
+package appname
+
+import (
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		ref      string
+		expected QualifiedName
+		err      bool
+	}{
+		{ref: "app", expected: QualifiedName{Namespace: "argocd", Name: "app"}},
+		{ref: "team/app", expected: QualifiedName{Namespace: "team", Name: "app"}},
+		{ref: "team_app", expected: QualifiedName{Namespace: "team", Name: "app"}},
+		{ref: "", err: true},
+		{ref: "/app", err: true},
+		{ref: "team/", err: true},
+		{ref: "team/sub/app", err: true},
+		{ref: "team/sub_app", err: true},
+	} {
+		name, err := Parse(tc.ref, "argocd")
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("Parse(%q): expected an error, got %v", tc.ref, name)
+		case !tc.err && err != nil:
+			t.Errorf("Parse(%q): unexpected error %v", tc.ref, err)
+		case name != tc.expected:
+			t.Errorf("Parse(%q) = %v, want %v", tc.ref, name, tc.expected)
+		}
+	}
+}
+
+func TestQualifiedNameFormats(t *testing.T) {
+	for _, tc := range []struct {
+		name                QualifiedName
+		project             string
+		str, instance, rbac string
+	}{
+		{name: QualifiedName{Name: "app"}, str: "app", instance: "app", rbac: "default/app"},
+		{name: QualifiedName{Namespace: "argocd", Name: "app"}, project: "p", str: "argocd/app", instance: "app", rbac: "p/app"},
+		{name: QualifiedName{Namespace: "team", Name: "app"}, project: "p", str: "team/app", instance: "team_app", rbac: "p/team/app"},
+	} {
+		if s := tc.name.String(); s != tc.str {
+			t.Errorf("%#v.String() = %q, want %q", tc.name, s, tc.str)
+		}
+		if s := tc.name.InstanceName("argocd"); s != tc.instance {
+			t.Errorf("%#v.InstanceName() = %q, want %q", tc.name, s, tc.instance)
+		}
+		if s := tc.name.RBACName(tc.project, "argocd"); s != tc.rbac {
+			t.Errorf("%#v.RBACName() = %q, want %q", tc.name, s, tc.rbac)
+		}
+		if parsed, err := Parse(tc.name.InstanceName("argocd"), "argocd"); err != nil || parsed.Name != tc.name.Name {
+			t.Errorf("instance name of %#v does not parse back: %v, %v", tc.name, parsed, err)
+		}
+	}
+}
+
+func TestRBACNameControllerNamespace(t *testing.T) {
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team"}}
+	app.Spec.Project = "p"
+	if name := RBACName(app, "argocd"); name != "p/team/app" {
+		t.Errorf("unexpected RBAC name %q", name)
+	}
+	app.Status.ControllerNamespace = "team"
+	if name := RBACName(app, "argocd"); name != "p/app" {
+		t.Errorf("expected the reported controller namespace to be used, got %q", name)
+	}
+}
+
+func TestIsNamespaceAllowed(t *testing.T) {
+	project := &v1alpha1.AppProject{ObjectMeta: metav1.ObjectMeta{Name: "p"}}
+	project.Spec.SourceNamespaces = []string{"team-*"}
+	for _, tc := range []struct {
+		namespace string
+		allowed   bool
+	}{
+		{namespace: "", allowed: true},
+		{namespace: "argocd", allowed: true},
+		{namespace: "team-a", allowed: true},
+		{namespace: "other"},
+	} {
+		app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: tc.namespace}}
+		allowed, err := IsNamespaceAllowed(app, project, "argocd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed != tc.allowed {
+			t.Errorf("namespace %q: allowed = %v, want %v", tc.namespace, allowed, tc.allowed)
+		}
+		if err := ValidateNamespace(app, project, "argocd"); (err == nil) != tc.allowed {
+			t.Errorf("namespace %q: unexpected validation result %v", tc.namespace, err)
+		}
+	}
+
+	project.Spec.SourceNamespaces = []string{"["}
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "other"}}
+	if _, err := IsNamespaceAllowed(app, project, "argocd"); err == nil {
+		t.Error("expected an error for an invalid source namespace")
+	}
+}
@@ -0,0 +1,190 @@
+// Package contenthash computes stable hashes of application sources and detects whether the comparison
+// recorded in an Application's sync status is outdated.
+package contenthash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// Source returns the hash of a source. Sources that only differ in map ordering, nil versus empty or zero
+// fields, or in the way their Helm values are written, such as Values YAML versus an equal ValuesObject, have
+// the same hash.
+func Source(source *v1alpha1.ApplicationSource) (string, error) {
+	value, err := canonicalSource(source)
+	if err != nil {
+		return "", err
+	}
+	return hash(value)
+}
+
+// Sources returns the hash of a list of sources, see Source. The order of the sources matters.
+func Sources(sources v1alpha1.ApplicationSources) (string, error) {
+	value, err := canonicalSources(sources)
+	if err != nil {
+		return "", err
+	}
+	return hash(value)
+}
+
+// ComparedTo returns the hash of what a comparison was performed against, see Source. nil has the hash of an
+// empty ComparedTo.
+func ComparedTo(comparedTo *v1alpha1.ComparedTo) (string, error) {
+	if comparedTo == nil {
+		comparedTo = &v1alpha1.ComparedTo{}
+	}
+	value := map[string]any{}
+	source, err := canonicalSource(&comparedTo.Source)
+	if err != nil {
+		return "", fmt.Errorf("source: %w", err)
+	}
+	sources, err := canonicalSources(comparedTo.Sources)
+	if err != nil {
+		return "", err
+	}
+	destination, err := canonical(comparedTo.Destination)
+	if err != nil {
+		return "", fmt.Errorf("destination: %w", err)
+	}
+	ignoreDifferences, err := canonical(comparedTo.IgnoreDifferences)
+	if err != nil {
+		return "", fmt.Errorf("ignoreDifferences: %w", err)
+	}
+	setIfNotNil(value, "source", source)
+	setIfNotNil(value, "sources", sources)
+	setIfNotNil(value, "destination", destination)
+	setIfNotNil(value, "ignoreDifferences", ignoreDifferences)
+	return hash(value)
+}
+
+// canonicalSource converts a source to a JSON value without zero fields, with the Helm values parsed and
+// stored under helm.values. Zero values inside the Helm values are kept, as they are meaningful to charts.
+func canonicalSource(source *v1alpha1.ApplicationSource) (any, error) {
+	if source == nil {
+		return nil, nil
+	}
+	stripped := source.DeepCopy()
+	var values any
+	if helm := stripped.Helm; helm != nil {
+		// empty values are absent values, no matter if set as empty YAML or as empty object
+		if !helm.ValuesIsEmpty() {
+			if err := yaml.Unmarshal(helm.ValuesYAML(), &values); err != nil {
+				return nil, fmt.Errorf("invalid helm values: %w", err)
+			}
+		}
+		helm.Values, helm.ValuesObject = "", nil
+	}
+	value, err := canonical(stripped)
+	if err != nil {
+		return nil, err
+	}
+	if values != nil {
+		m, _ := value.(map[string]any)
+		if m == nil {
+			m = map[string]any{}
+		}
+		helm, _ := m["helm"].(map[string]any)
+		if helm == nil {
+			helm = map[string]any{}
+		}
+		helm["values"] = values
+		m["helm"] = helm
+		value = m
+	}
+	return value, nil
+}
+
+func canonicalSources(sources v1alpha1.ApplicationSources) (any, error) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	values := make([]any, 0, len(sources))
+	for i := range sources {
+		value, err := canonicalSource(&sources[i])
+		if err != nil {
+			return nil, fmt.Errorf("sources[%d]: %w", i, err)
+		}
+		// keep empty sources, so the positions of the others do not shift
+		if value == nil {
+			value = map[string]any{}
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// canonical converts v to a JSON value and drops all zero values
+func canonical(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return prune(value), nil
+}
+
+// prune removes nil, false, 0, empty strings, empty lists and empty maps, returning nil if nothing is left
+func prune(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if pruned := prune(child); pruned == nil {
+				delete(v, key)
+			} else {
+				v[key] = pruned
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		// keep the positions of list items, so pruned items become empty maps
+		for i, item := range v {
+			if v[i] = prune(item); v[i] == nil {
+				v[i] = map[string]any{}
+			}
+		}
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+	case bool:
+		if !v {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	}
+	return value
+}
+
+func setIfNotNil(m map[string]any, key string, value any) {
+	if value != nil {
+		m[key] = value
+	}
+}
+
+// hash returns the hex encoded SHA-256 of the JSON encoding of value, whose map keys encoding/json sorts
+func hash(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
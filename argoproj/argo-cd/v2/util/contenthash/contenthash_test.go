@@ -0,0 +1,168 @@
+package contenthash
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func mustSource(t *testing.T, source *v1alpha1.ApplicationSource) string {
+	t.Helper()
+	h, err := Source(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func helmSource(helm *v1alpha1.ApplicationSourceHelm) *v1alpha1.ApplicationSource {
+	return &v1alpha1.ApplicationSource{RepoURL: "https://charts.example.com", Chart: "app", TargetRevision: "1.0.0", Helm: helm}
+}
+
+func valuesObject(raw string) *runtime.RawExtension {
+	return &runtime.RawExtension{Raw: []byte(raw)}
+}
+
+func TestSourceEqual(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a, b *v1alpha1.ApplicationSource
+	}{
+		{
+			name: "values map order",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: "a: 1\nb:\n  c: x\n  d: w\n"}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: "b:\n  d: w\n  c: x\na: 1\n"}),
+		},
+		{
+			name: "values object key order",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{ValuesObject: valuesObject(`{"a":1,"b":{"c":"x"}}`)}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{ValuesObject: valuesObject(`{"b":{"c":"x"},"a":1}`)}),
+		},
+		{
+			name: "values versus values object",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: "a: 1\nb: [x, z]\n"}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{ValuesObject: valuesObject(`{"b":["x","z"],"a":1}`)}),
+		},
+		{
+			name: "empty values versus empty values object",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: ""}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{ValuesObject: valuesObject(`{}`)}),
+		},
+		{
+			name: "empty helm versus no helm",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{ValuesObject: valuesObject(`{}`)}),
+			b:    helmSource(nil),
+		},
+		{
+			name: "nil versus empty lists",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{ReleaseName: "r", Parameters: []v1alpha1.HelmParameter{}, ValueFiles: []string{}}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{ReleaseName: "r"}),
+		},
+		{
+			name: "nil versus empty maps",
+			a: &v1alpha1.ApplicationSource{RepoURL: "https://git.example.com", Kustomize: &v1alpha1.ApplicationSourceKustomize{
+				NamePrefix:   "p-",
+				CommonLabels: map[string]string{},
+			}},
+			b: &v1alpha1.ApplicationSource{RepoURL: "https://git.example.com", Kustomize: &v1alpha1.ApplicationSourceKustomize{NamePrefix: "p-"}},
+		},
+		{
+			name: "nil versus empty source",
+			a:    nil,
+			b:    &v1alpha1.ApplicationSource{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if a, b := mustSource(t, tc.a), mustSource(t, tc.b); a != b {
+				t.Errorf("expected equal hashes, got %s and %s", a, b)
+			}
+		})
+	}
+}
+
+func TestSourceDifferent(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a, b *v1alpha1.ApplicationSource
+	}{
+		{
+			name: "values",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: "a: 1\n"}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: "a: 2\n"}),
+		},
+		{
+			name: "zero value inside values",
+			a:    helmSource(&v1alpha1.ApplicationSourceHelm{Values: "replicas: 0\n"}),
+			b:    helmSource(&v1alpha1.ApplicationSourceHelm{}),
+		},
+		{
+			name: "revision",
+			a:    helmSource(nil),
+			b:    &v1alpha1.ApplicationSource{RepoURL: "https://charts.example.com", Chart: "app", TargetRevision: "1.0.1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if a, b := mustSource(t, tc.a), mustSource(t, tc.b); a == b {
+				t.Errorf("expected different hashes, got %s", a)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	a, err := Sources(v1alpha1.ApplicationSources{*helmSource(nil), {RepoURL: "https://git.example.com", Ref: "values"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Sources(v1alpha1.ApplicationSources{{RepoURL: "https://git.example.com", Ref: "values"}, *helmSource(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("expected the order of sources to matter")
+	}
+}
+
+func TestComparedTo(t *testing.T) {
+	empty, err := ComparedTo(&v1alpha1.ComparedTo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nilHash, err := ComparedTo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty != nilHash {
+		t.Errorf("expected nil and empty to have the same hash, got %s and %s", nilHash, empty)
+	}
+}
+
+func TestComparedToStale(t *testing.T) {
+	app := &v1alpha1.Application{}
+	app.Spec.Source = helmSource(&v1alpha1.ApplicationSourceHelm{Values: "a: 1\n"})
+	app.Spec.Destination = v1alpha1.ApplicationDestination{Server: "https://kubernetes.default.svc", Namespace: "app"}
+	app.Status.Sync.ComparedTo = v1alpha1.ComparedTo{
+		Source:      *helmSource(&v1alpha1.ApplicationSourceHelm{ValuesObject: valuesObject(`{"a":1}`)}),
+		Destination: app.Spec.Destination,
+	}
+
+	reasons, err := ComparedToStale(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reasons) != 0 {
+		t.Errorf("expected comparison to be up to date, got %v", reasons)
+	}
+
+	app.Spec.Destination.Namespace = "other"
+	app.Spec.IgnoreDifferences = v1alpha1.IgnoreDifferences{{Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}}}
+	reasons, err = ComparedToStale(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(reasons, []StaleReason{StaleReasonDestination, StaleReasonIgnoreDifferences}) {
+		t.Errorf("unexpected reasons %v", reasons)
+	}
+}
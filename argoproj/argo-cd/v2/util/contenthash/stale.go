@@ -0,0 +1,102 @@
+package contenthash
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// StaleReason names the part of the spec that changed since the last comparison
+type StaleReason string
+
+const (
+	StaleReasonSource            StaleReason = "source"
+	StaleReasonDestination       StaleReason = "destination"
+	StaleReasonIgnoreDifferences StaleReason = "ignoreDifferences"
+)
+
+// ComparedToStale returns the parts of the spec of app that differ from what Status.Sync.ComparedTo was
+// compared against, or nil if the comparison is up to date. The comparison is compared with the same
+// normalization as the hashes of this package. An application without a comparison is not stale.
+func ComparedToStale(app *v1alpha1.Application) ([]StaleReason, error) {
+	comparedTo := &app.Status.Sync.ComparedTo
+	if isZeroComparedTo(comparedTo) {
+		return nil, nil
+	}
+
+	var reasons []StaleReason
+	var specSource, comparedSource any
+	var err error
+	if app.Spec.HasMultipleSources() {
+		specSource, err = canonicalSources(app.Spec.Sources)
+		if err == nil {
+			comparedSource, err = canonicalSources(comparedTo.Sources)
+		}
+	} else {
+		specSource, err = canonicalSource(app.Spec.Source)
+		if err == nil {
+			comparedSource, err = canonicalSource(&comparedTo.Source)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare sources: %w", err)
+	}
+	if changed, err := differ(specSource, comparedSource); err != nil {
+		return nil, err
+	} else if changed {
+		reasons = append(reasons, StaleReasonSource)
+	}
+
+	specDestination, comparedDestination := app.Spec.Destination, comparedTo.Destination
+	// the server is inferred from the name by the controller, so only the name is compared if both have one
+	if specDestination.Name != "" && comparedDestination.Name != "" {
+		specDestination.Server, comparedDestination.Server = "", ""
+	}
+	if changed, err := differCanonical(specDestination, comparedDestination); err != nil {
+		return nil, err
+	} else if changed {
+		reasons = append(reasons, StaleReasonDestination)
+	}
+
+	if changed, err := differCanonical(app.Spec.IgnoreDifferences, comparedTo.IgnoreDifferences); err != nil {
+		return nil, err
+	} else if changed {
+		reasons = append(reasons, StaleReasonIgnoreDifferences)
+	}
+	return reasons, nil
+}
+
+// IsComparedToStale returns true if the comparison of app is outdated, see ComparedToStale
+func IsComparedToStale(app *v1alpha1.Application) (bool, error) {
+	reasons, err := ComparedToStale(app)
+	return len(reasons) > 0, err
+}
+
+func isZeroComparedTo(comparedTo *v1alpha1.ComparedTo) bool {
+	value, err := canonical(comparedTo)
+	return err == nil && value == nil
+}
+
+func differCanonical(a, b any) (bool, error) {
+	ca, err := canonical(a)
+	if err != nil {
+		return false, err
+	}
+	cb, err := canonical(b)
+	if err != nil {
+		return false, err
+	}
+	return differ(ca, cb)
+}
+
+func differ(a, b any) (bool, error) {
+	ha, err := hash(a)
+	if err != nil {
+		return false, err
+	}
+	hb, err := hash(b)
+	if err != nil {
+		return false, err
+	}
+	return ha != hb, nil
+}
@@ -0,0 +1,112 @@
+// Package directory selects and renders the manifests of plain directory
+// application sources the same way the Argo CD repo server does.
+package directory
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+
+	"github.com/gobwas/glob"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// manifestFile matches the file names the repo server considers as manifests
+var manifestFile = regexp.MustCompile(`^.*\.(yaml|yml|json|jsonnet)$`)
+
+// FindManifests walks the directory root of fsys and returns the paths of all files that Argo CD would use
+// to generate manifests for a directory source with the given options. Paths are slash separated, relative
+// to root and returned in lexical order.
+//
+// Only files with a .yaml, .yml, .json or .jsonnet extension are selected. Subdirectories are only scanned
+// if Recurse is set. Hidden files and directories are not treated specially, matching the repo server.
+// Include and Exclude are matched against the relative path of a file and support the usual glob syntax
+// including brace expansion such as {a.yaml,b/*.json}. As no path separator is configured, * also matches
+// across directories. Exclude takes precedence over Include.
+func FindManifests(fsys fs.FS, root string, directory *v1alpha1.ApplicationSourceDirectory) ([]string, error) {
+	if directory == nil {
+		directory = &v1alpha1.ApplicationSourceDirectory{}
+	}
+	include, err := compilePattern(directory.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	exclude, err := compilePattern(directory.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	root = path.Clean(root)
+	var manifests []string
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && !directory.Recurse {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !manifestFile.MatchString(d.Name()) {
+			return nil
+		}
+		relPath := relativePath(root, p)
+		if exclude != nil && exclude.Match(relPath) {
+			return nil
+		}
+		if include != nil && !include.Match(relPath) {
+			return nil
+		}
+		manifests = append(manifests, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// IsManifest returns true if the file at relPath, relative to the source directory, would be selected by
+// FindManifests. It does not check whether the file exists.
+func IsManifest(relPath string, directory *v1alpha1.ApplicationSourceDirectory) (bool, error) {
+	if directory == nil {
+		directory = &v1alpha1.ApplicationSourceDirectory{}
+	}
+	relPath = path.Clean(relPath)
+	if !manifestFile.MatchString(path.Base(relPath)) {
+		return false, nil
+	}
+	if !directory.Recurse && path.Dir(relPath) != "." {
+		return false, nil
+	}
+	exclude, err := compilePattern(directory.Exclude)
+	if err != nil {
+		return false, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	if exclude != nil && exclude.Match(relPath) {
+		return false, nil
+	}
+	include, err := compilePattern(directory.Include)
+	if err != nil {
+		return false, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	return include == nil || include.Match(relPath), nil
+}
+
+// compilePattern compiles a directory include or exclude pattern. It returns nil for an empty pattern.
+func compilePattern(pattern string) (glob.Glob, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return glob.Compile(pattern)
+}
+
+// relativePath returns p relative to root, both being cleaned slash separated paths within the same fs.FS
+func relativePath(root, p string) string {
+	if root == "." {
+		return p
+	}
+	return p[len(root)+1:]
+}
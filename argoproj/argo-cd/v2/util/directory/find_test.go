@@ -0,0 +1,125 @@
+package directory
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+func TestFindManifests(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		directory *v1alpha1.ApplicationSourceDirectory
+		expected  []string
+		err       bool
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"config.json", "deployment.yaml", "list.yml", "values.yaml"},
+		},
+		{
+			name:      "recurse false",
+			directory: &v1alpha1.ApplicationSourceDirectory{Recurse: false, Include: "*.yaml"},
+			expected:  []string{"deployment.yaml", "values.yaml"},
+		},
+		{
+			name:      "recurse",
+			directory: &v1alpha1.ApplicationSourceDirectory{Recurse: true},
+			expected:  []string{"config.json", "deployment.yaml", "list.yml", "sub/nested/job.yaml", "sub/secret.yaml", "values.yaml"},
+		},
+		{
+			name:      "include matches across directories",
+			directory: &v1alpha1.ApplicationSourceDirectory{Recurse: true, Include: "*.yaml"},
+			expected:  []string{"deployment.yaml", "sub/nested/job.yaml", "sub/secret.yaml", "values.yaml"},
+		},
+		{
+			name:      "exclude",
+			directory: &v1alpha1.ApplicationSourceDirectory{Recurse: true, Exclude: "sub/*"},
+			expected:  []string{"config.json", "deployment.yaml", "list.yml", "values.yaml"},
+		},
+		{
+			name:      "include with braces and exclude",
+			directory: &v1alpha1.ApplicationSourceDirectory{Recurse: true, Include: "{deployment.yaml,sub/*}", Exclude: "sub/nested/*"},
+			expected:  []string{"deployment.yaml", "sub/secret.yaml"},
+		},
+		{
+			name:      "exclude takes precedence over include",
+			directory: &v1alpha1.ApplicationSourceDirectory{Include: "deployment.yaml", Exclude: "deployment.yaml"},
+		},
+		{
+			name:      "invalid include",
+			directory: &v1alpha1.ApplicationSourceDirectory{Include: "["},
+			err:       true,
+		},
+		{
+			name:      "invalid exclude",
+			directory: &v1alpha1.ApplicationSourceDirectory{Exclude: "[a-"},
+			err:       true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := FindManifests(os.DirFS("testdata"), "app", tc.directory)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", manifests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(manifests, tc.expected) {
+				t.Errorf("unexpected manifests %v", manifests)
+			}
+		})
+	}
+}
+
+func TestFindManifestsRoot(t *testing.T) {
+	manifests, err := FindManifests(os.DirFS("testdata/app"), ".", &v1alpha1.ApplicationSourceDirectory{Recurse: true, Include: "sub/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"sub/nested/job.yaml", "sub/secret.yaml"}; !slices.Equal(manifests, expected) {
+		t.Errorf("unexpected manifests %v", manifests)
+	}
+}
+
+func TestIsManifest(t *testing.T) {
+	for _, tc := range []struct {
+		path      string
+		directory *v1alpha1.ApplicationSourceDirectory
+		expected  bool
+	}{
+		{path: "deployment.yaml", expected: true},
+		{path: "./deployment.yaml", expected: true},
+		{path: "README.md"},
+		{path: "sub/secret.yaml"},
+		{path: "sub/secret.yaml", directory: &v1alpha1.ApplicationSourceDirectory{Recurse: true}, expected: true},
+		{path: "sub/secret.yaml", directory: &v1alpha1.ApplicationSourceDirectory{Recurse: true, Exclude: "sub/*"}},
+		{path: "deployment.yaml", directory: &v1alpha1.ApplicationSourceDirectory{Include: "*.json"}},
+		{path: "deployment.yaml", directory: &v1alpha1.ApplicationSourceDirectory{Include: "*.yaml", Exclude: "deploy*"}},
+	} {
+		matched, err := IsManifest(tc.path, tc.directory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != tc.expected {
+			t.Errorf("IsManifest(%q, %+v) = %v, want %v", tc.path, tc.directory, matched, tc.expected)
+		}
+	}
+
+	// IsManifest agrees with FindManifests
+	directory := &v1alpha1.ApplicationSourceDirectory{Recurse: true, Include: "{deployment.yaml,sub/*}", Exclude: "sub/nested/*"}
+	manifests, err := FindManifests(os.DirFS("testdata"), "app", directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"config.json", "deployment.yaml", "list.yml", "sub/nested/job.yaml", "sub/secret.yaml", "values.yaml"} {
+		if matched, _ := IsManifest(p, directory); matched != slices.Contains(manifests, p) {
+			t.Errorf("IsManifest(%q) = %v disagrees with FindManifests %v", p, matched, manifests)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package directory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrJsonnetNotSupported is returned when a directory source selects a Jsonnet file, which cannot be rendered in-process
+var ErrJsonnetNotSupported = errors.New("jsonnet manifests are not supported")
+
+// Manifest is a single object rendered from a directory source
+type Manifest struct {
+	// Path is the slash separated path of the file the object was read from, relative to the source directory
+	Path string
+	// Object is the rendered object
+	Object unstructured.Unstructured
+}
+
+// ParseError indicates that a manifest file contains a document that is not a valid Kubernetes object
+type ParseError struct {
+	// Path is the path of the file, relative to the source directory
+	Path string
+	// Document is the zero based index of the offending document within a multi-document YAML file
+	Document int
+	// Err is the underlying decoding error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to unmarshal %q (document %d): %v", e.Path, e.Document, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Render reads all manifests selected by FindManifests from the directory root of fsys and returns the
+// objects they contain in file order.
+//
+// YAML files may contain multiple documents, empty documents are skipped. Objects of a List kind are
+// expanded into their items. As with the repo server, a YAML file that cannot be parsed is ignored unless
+// it contains at least one valid object or looks like a Kubernetes manifest, in which case a *ParseError
+// is returned. JSON files must contain exactly one object. Jsonnet files result in ErrJsonnetNotSupported.
+func Render(fsys fs.FS, root string, directory *v1alpha1.ApplicationSourceDirectory) ([]Manifest, error) {
+	files, err := FindManifests(fsys, root, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []Manifest
+	for _, file := range files {
+		if strings.HasSuffix(file, ".jsonnet") {
+			return nil, fmt.Errorf("%q: %w", file, ErrJsonnetNotSupported)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(root, file))
+		if err != nil {
+			return nil, err
+		}
+		objs, err := parseManifest(file, data)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			items, err := expandList(file, obj)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				manifests = append(manifests, Manifest{Path: file, Object: *item})
+			}
+		}
+	}
+	return manifests, nil
+}
+
+// Objects returns the objects of the given manifests
+func Objects(manifests []Manifest) []unstructured.Unstructured {
+	objs := make([]unstructured.Unstructured, 0, len(manifests))
+	for _, m := range manifests {
+		objs = append(objs, m.Object)
+	}
+	return objs
+}
+
+// parseManifest decodes the objects contained in a single manifest file
+func parseManifest(file string, data []byte) ([]*unstructured.Unstructured, error) {
+	if strings.HasSuffix(file, ".json") {
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(data, obj); err != nil {
+			return nil, &ParseError{Path: file, Err: err}
+		}
+		return []*unstructured.Unstructured{obj}, nil
+	}
+
+	objs, err := splitYAML(file, data)
+	if err != nil {
+		if len(objs) > 0 || looksLikeManifest(data) {
+			return nil, err
+		}
+		// probably an unrelated YAML file that happens to live next to the manifests
+		return nil, nil
+	}
+	return objs, nil
+}
+
+// splitYAML decodes a multi-document YAML or JSON stream into objects
+func splitYAML(file string, data []byte) ([]*unstructured.Unstructured, error) {
+	d := kubeyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var objs []*unstructured.Unstructured
+	for i := 0; ; i++ {
+		ext := runtime.RawExtension{}
+		if err := d.Decode(&ext); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return objs, &ParseError{Path: file, Document: i, Err: err}
+		}
+		ext.Raw = bytes.TrimSpace(ext.Raw)
+		if len(ext.Raw) == 0 || bytes.Equal(ext.Raw, []byte("null")) {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(ext.Raw, obj); err != nil {
+			return objs, &ParseError{Path: file, Document: i, Err: err}
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// looksLikeManifest returns true if data seems to be meant as a Kubernetes manifest
+func looksLikeManifest(data []byte) bool {
+	return bytes.Contains(data, []byte("apiVersion:")) && bytes.Contains(data, []byte("kind:")) && bytes.Contains(data, []byte("metadata:"))
+}
+
+// expandList returns the items of a List object or the object itself otherwise
+func expandList(file string, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if !obj.IsList() {
+		if strings.HasSuffix(obj.GetKind(), "List") {
+			if items, ok := obj.Object["items"]; ok && items == nil {
+				// list without any items
+				return nil, nil
+			}
+		}
+		return []*unstructured.Unstructured{obj}, nil
+	}
+
+	var items []*unstructured.Unstructured
+	err := obj.EachListItem(func(item runtime.Object) error {
+		u, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("resource list item has unexpected type %T", item)
+		}
+		items = append(items, u)
+		return nil
+	})
+	if err != nil {
+		return nil, &ParseError{Path: file, Err: err}
+	}
+	return items, nil
+}
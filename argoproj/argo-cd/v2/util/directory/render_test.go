@@ -0,0 +1,79 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRender(t *testing.T) {
+	manifests, err := Render(os.DirFS("testdata"), "app", &v1alpha1.ApplicationSourceDirectory{Recurse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rendered []string
+	for _, m := range manifests {
+		rendered = append(rendered, m.Path+": "+m.Object.GetKind()+"/"+m.Object.GetName())
+	}
+	expected := []string{
+		"config.json: ConfigMap/guestbook-config",
+		"deployment.yaml: Deployment/guestbook-ui",
+		"deployment.yaml: Service/guestbook-ui",
+		"list.yml: ConfigMap/list-a",
+		"list.yml: ConfigMap/list-b",
+		"sub/nested/job.yaml: Job/guestbook-migrate",
+		"sub/secret.yaml: Secret/guestbook-secret",
+	}
+	if !slices.Equal(rendered, expected) {
+		t.Errorf("unexpected manifests %q", rendered)
+	}
+
+	objs := Objects(manifests)
+	if len(objs) != len(manifests) {
+		t.Fatalf("expected %d objects, got %d", len(manifests), len(objs))
+	}
+	if replicas, _, _ := unstructured.NestedFieldNoCopy(objs[1].Object, "spec", "replicas"); replicas != int64(1) {
+		t.Errorf("expected replicas to be decoded as int64, got %T %v", replicas, replicas)
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		file       string
+		parseError bool
+		document   int
+		err        error
+	}{
+		{file: "broken.yaml", parseError: true},
+		{file: "broken.json", parseError: true},
+		{file: "partial.yaml", parseError: true, document: 1},
+		{file: "unrelated.yaml"},
+		{file: "app.jsonnet", err: ErrJsonnetNotSupported},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			manifests, err := Render(os.DirFS("testdata"), "invalid", &v1alpha1.ApplicationSourceDirectory{Include: tc.file})
+			var parseErr *ParseError
+			switch {
+			case tc.parseError:
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("expected a parse error, got %v", err)
+				}
+				if parseErr.Path != tc.file || parseErr.Document != tc.document {
+					t.Errorf("unexpected parse error %v", parseErr)
+				}
+			case tc.err != nil:
+				if !errors.Is(err, tc.err) {
+					t.Errorf("expected %v, got %v", tc.err, err)
+				}
+			default:
+				if err != nil || len(manifests) != 0 {
+					t.Errorf("expected the file to be ignored, got %v, %v", manifests, err)
+				}
+			}
+		})
+	}
+}
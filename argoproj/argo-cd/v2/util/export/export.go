@@ -0,0 +1,171 @@
+// Package export converts live Applications and AppProjects into clean declarative manifests, suitable
+// to be stored in git.
+package export
+
+import (
+	"slices"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/util/tracking"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AnnotationKeyNotified is the annotation in which the notifications controller records sent notifications
+const AnnotationKeyNotified = "notified.notifications.argoproj.io"
+
+// ControllerAnnotations are the annotations set by Argo CD and kubectl rather than by users, which are
+// removed on export
+var ControllerAnnotations = []string{
+	corev1.LastAppliedConfigAnnotation,
+	v1alpha1.AnnotationKeyRefresh,
+	v1alpha1.AnnotationKeyHydrate,
+	tracking.AnnotationKeyAppInstance,
+	AnnotationKeyNotified,
+}
+
+// Application returns the clean manifest of app as YAML, see CleanApplication
+func Application(app *v1alpha1.Application) ([]byte, error) {
+	return toYAML(CleanApplication(app))
+}
+
+// AppProject returns the clean manifest of proj as YAML, see CleanAppProject
+func AppProject(proj *v1alpha1.AppProject) ([]byte, error) {
+	return toYAML(CleanAppProject(proj))
+}
+
+// CleanApplication returns a copy of app without status, operation, server-populated metadata and
+// controller annotations, and with all fields equal to their defaults removed
+func CleanApplication(app *v1alpha1.Application) *v1alpha1.Application {
+	clean := &v1alpha1.Application{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       application.ApplicationKind,
+		},
+		ObjectMeta: cleanObjectMeta(&app.ObjectMeta),
+		Spec:       *app.Spec.DeepCopy(),
+	}
+
+	spec := &clean.Spec
+	if spec.RevisionHistoryLimit != nil && *spec.RevisionHistoryLimit == v1alpha1.DefaultRevisionHistoryLimit {
+		spec.RevisionHistoryLimit = nil
+	}
+	if spec.Source != nil {
+		cleanSource(spec.Source)
+		if spec.Source.IsZero() {
+			spec.Source = nil
+		}
+	}
+	for i := range spec.Sources {
+		cleanSource(&spec.Sources[i])
+	}
+	if len(spec.Sources) == 0 {
+		spec.Sources = nil
+	}
+	spec.SyncPolicy = cleanSyncPolicy(spec.SyncPolicy)
+	if len(spec.IgnoreDifferences) == 0 {
+		spec.IgnoreDifferences = nil
+	}
+	if len(spec.Info) == 0 {
+		spec.Info = nil
+	}
+	return clean
+}
+
+// CleanAppProject returns a copy of proj without status, server-populated metadata and controller
+// annotations
+func CleanAppProject(proj *v1alpha1.AppProject) *v1alpha1.AppProject {
+	return &v1alpha1.AppProject{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       application.AppProjectKind,
+		},
+		ObjectMeta: cleanObjectMeta(&proj.ObjectMeta),
+		Spec:       *proj.Spec.DeepCopy(),
+	}
+}
+
+// cleanObjectMeta keeps the fields of meta that are owned by users. Owner references are dropped too, as
+// they refer to uids that do not survive a restore, and so are the post-delete finalizers the controller
+// manages. Resources finalizers are kept, as users set them to choose how an application is deleted.
+func cleanObjectMeta(meta *metav1.ObjectMeta) metav1.ObjectMeta {
+	clean := metav1.ObjectMeta{
+		Name:       meta.Name,
+		Namespace:  meta.Namespace,
+		Labels:     copyMap(meta.Labels),
+		Finalizers: slices.DeleteFunc(slices.Clone(meta.Finalizers), v1alpha1.IsPostDeleteFinalizer),
+	}
+	if meta.Name == "" {
+		clean.GenerateName = meta.GenerateName
+	}
+	annotations := copyMap(meta.Annotations)
+	for _, key := range ControllerAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) > 0 {
+		clean.Annotations = annotations
+	}
+	if len(clean.Finalizers) == 0 {
+		clean.Finalizers = nil
+	}
+	return clean
+}
+
+func cleanSource(source *v1alpha1.ApplicationSource) {
+	// an empty revision means HEAD for git repositories, but charts require a version
+	if source.Chart == "" && source.TargetRevision == "HEAD" {
+		source.TargetRevision = ""
+	}
+	if helm := source.Helm; helm != nil {
+		if helm.ValuesObject != nil && len(helm.ValuesObject.Raw) == 0 {
+			helm.ValuesObject = nil
+		}
+		if isZero(helm) {
+			source.Helm = nil
+		}
+	}
+	if isZero(source.Kustomize) {
+		source.Kustomize = nil
+	}
+	if isZero(source.Directory) {
+		source.Directory = nil
+	}
+	if isZero(source.Plugin) {
+		source.Plugin = nil
+	}
+}
+
+func cleanSyncPolicy(policy *v1alpha1.SyncPolicy) *v1alpha1.SyncPolicy {
+	if policy == nil {
+		return nil
+	}
+	// automated sync is enabled unless explicitly disabled
+	if automated := policy.Automated; automated != nil && automated.Enabled != nil && *automated.Enabled {
+		automated.Enabled = nil
+	}
+	if retry := policy.Retry; retry != nil && retry.Backoff.IsDefault() {
+		retry.Backoff = nil
+	}
+	if len(policy.SyncOptions) == 0 {
+		policy.SyncOptions = nil
+	}
+	if isZero(policy.ManagedNamespaceMetadata) {
+		policy.ManagedNamespaceMetadata = nil
+	}
+	if isZero(policy) {
+		return nil
+	}
+	return policy
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
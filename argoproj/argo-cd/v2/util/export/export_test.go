@@ -0,0 +1,127 @@
+package export
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestApplicationRoundTrip(t *testing.T) {
+	values := []string{
+		"yes", "no", "on", "off", "y", "N", "True", "null", "~", "", "1:20", "0x1F", "010", "1_000", "1e3",
+		"plain", "multi\nline\n",
+	}
+	var parameters []v1alpha1.HelmParameter
+	for _, value := range values {
+		parameters = append(parameters, v1alpha1.HelmParameter{Name: "p", Value: value})
+	}
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "argocd",
+			Labels:    map[string]string{"on": "off"},
+		},
+		Spec: v1alpha1.ApplicationSpec{
+			Project: "default",
+			Source: &v1alpha1.ApplicationSource{
+				RepoURL: "https://example.com/repo.git",
+				Path:    "chart",
+				Helm:    &v1alpha1.ApplicationSourceHelm{Parameters: parameters},
+			},
+			Destination: v1alpha1.ApplicationDestination{Server: "https://kubernetes.default.svc", Namespace: "yes"},
+		},
+	}
+
+	data, err := Application(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored v1alpha1.Application
+	if err := yaml.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("failed to read back export: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(restored.Spec, app.Spec) {
+		t.Errorf("spec changed by round trip:\n%s", data)
+	}
+	if !reflect.DeepEqual(restored.Labels, app.Labels) {
+		t.Errorf("labels changed by round trip: %v", restored.Labels)
+	}
+}
+
+func TestApplicationDefaults(t *testing.T) {
+	limit := int64(v1alpha1.DefaultRevisionHistoryLimit)
+	enabled := true
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "app",
+			Namespace:       "argocd",
+			UID:             "uid",
+			ResourceVersion: "1",
+			Generation:      2,
+			Annotations:     map[string]string{v1alpha1.AnnotationKeyRefresh: "normal"},
+		},
+		Spec: v1alpha1.ApplicationSpec{
+			RevisionHistoryLimit: &limit,
+			Source:               &v1alpha1.ApplicationSource{RepoURL: "https://example.com/repo.git", TargetRevision: "HEAD"},
+			SyncPolicy: &v1alpha1.SyncPolicy{
+				Automated: &v1alpha1.SyncPolicyAutomated{Enabled: &enabled},
+				Retry:     &v1alpha1.RetryStrategy{Backoff: &v1alpha1.Backoff{Duration: "5", MaxDuration: "3m"}},
+			},
+		},
+		Operation: &v1alpha1.Operation{},
+	}
+	app.Status.Sync.Status = v1alpha1.SyncStatusCodeSynced
+
+	data, err := Application(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app
+  namespace: argocd
+spec:
+  source:
+    repoURL: https://example.com/repo.git
+  syncPolicy:
+    automated: {}
+    retry: {}
+`
+	if string(data) != expected {
+		t.Errorf("unexpected export:\n%s", data)
+	}
+	for _, unexpected := range []string{"status", "operation", "destination", "project"} {
+		if strings.Contains(string(data), unexpected) {
+			t.Errorf("export contains %s", unexpected)
+		}
+	}
+}
+
+func TestCleanApplicationFinalizers(t *testing.T) {
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{
+		Name:      "app",
+		Namespace: "argocd",
+		Finalizers: []string{
+			v1alpha1.BackgroundPropagationPolicyFinalizer,
+			v1alpha1.PostDeleteFinalizerName,
+			v1alpha1.PostDeleteFinalizerName + "/" + v1alpha1.PostDeleteFinalizerCleanupStage,
+		},
+	}}
+	clean := CleanApplication(app)
+	if expected := []string{v1alpha1.BackgroundPropagationPolicyFinalizer}; !reflect.DeepEqual(clean.Finalizers, expected) {
+		t.Errorf("unexpected finalizers %v", clean.Finalizers)
+	}
+	if len(app.Finalizers) != 3 {
+		t.Errorf("the finalizers of the application were modified: %v", app.Finalizers)
+	}
+
+	app.Finalizers = []string{v1alpha1.PostDeleteFinalizerName}
+	if clean := CleanApplication(app); clean.Finalizers != nil {
+		t.Errorf("expected no finalizers, got %v", clean.Finalizers)
+	}
+}
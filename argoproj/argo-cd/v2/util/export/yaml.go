@@ -0,0 +1,183 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// isZero returns true if v is nil or only holds zero values. Empty and nil slices and maps are considered
+// equal, as they are omitted from manifests alike.
+func isZero(v any) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return reflect.ValueOf(v).IsZero()
+	}
+	switch string(data) {
+	case "null", "{}":
+		return true
+	}
+	return false
+}
+
+// requiredSpecFields are the spec fields without omitempty. When empty they equal their defaults and are
+// dropped like all other fields.
+var requiredSpecFields = []string{"destination", "project"}
+
+// toYAML encodes obj as YAML. Unlike sigs.k8s.io/yaml, which sorts all keys, struct fields keep the order
+// of their declaration, as in the manifests of Argo CD, with apiVersion and kind first. Map keys are sorted
+// and null values are dropped.
+func toYAML(obj any) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeNode(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to yaml: %w", err)
+	}
+	if node == nil {
+		return nil, nil
+	}
+	moveFirst(node, "kind")
+	moveFirst(node, "apiVersion")
+	dropKey(node, "status")
+	if spec := lookup(node, "spec"); spec != nil {
+		for _, key := range requiredSpecFields {
+			if value := lookup(spec, key); value != nil && isEmptyNode(value) {
+				dropKey(spec, key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeNode decodes the next JSON value into a YAML node, returning nil for null
+func decodeNode(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected key %v", keyToken)
+				}
+				value, err := decodeNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				if value != nil {
+					node.Content = append(node.Content, stringNode(key), value)
+				}
+			}
+			_, err := decoder.Token()
+			return node, err
+		case '[':
+			node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for decoder.More() {
+				value, err := decodeNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				if value == nil {
+					value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+				}
+				node.Content = append(node.Content, value)
+			}
+			_, err := decoder.Token()
+			return node, err
+		}
+	case string:
+		return stringNode(t), nil
+	case json.Number:
+		tag := "!!int"
+		if _, err := t.Int64(); err != nil {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		value := "false"
+		if t {
+			value = "true"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, errors.New("unexpected json token")
+}
+
+// stringNode encodes s like yaml.Marshal does, which quotes strings that YAML 1.1 parsers, such as
+// sigs.k8s.io/yaml and kubectl, would read as booleans, numbers or null, and keeps multi-line strings,
+// such as helm values, readable
+func stringNode(s string) *yaml.Node {
+	node := &yaml.Node{}
+	if err := node.Encode(s); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s, Style: yaml.DoubleQuotedStyle}
+	}
+	return node
+}
+
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	case yaml.ScalarNode:
+		return node.Tag == "!!str" && node.Value == ""
+	}
+	return false
+}
+
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func moveFirst(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			pair := []*yaml.Node{node.Content[i], node.Content[i+1]}
+			node.Content = append(pair, append(node.Content[:i:i], node.Content[i+2:]...)...)
+			return
+		}
+	}
+}
+
+func dropKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package health assesses the health of Kubernetes resources the same way Argo CD's built-in health checks do.
+package health
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// hookFinalizer is the finalizer Argo CD adds to hooks to keep them around until their health was observed
+const hookFinalizer = "argocd.argoproj.io/hook-finalizer"
+
+// HealthCheck assesses the health of an object. It returns nil if the health of the object cannot be determined.
+type HealthCheck func(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error)
+
+// HealthOverride provides custom health checks that take precedence over the built-in ones, such as the
+// resource.customizations.health scripts of Argo CD
+type HealthOverride interface {
+	// GetResourceHealth returns the health of obj or nil if there is no custom health check for it
+	GetResourceHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error)
+}
+
+// Registry holds the health checks for resource kinds. It is safe for concurrent use.
+type Registry struct {
+	mutex  sync.RWMutex
+	checks map[schema.GroupKind]HealthCheck
+}
+
+// DefaultRegistry is the registry used by GetResourceHealth. It contains the built-in health checks and may be
+// extended with checks for custom resources.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns a registry that contains the built-in health checks of Argo CD
+func NewRegistry() *Registry {
+	r := &Registry{checks: map[schema.GroupKind]HealthCheck{}}
+	for gk, check := range builtinChecks {
+		r.checks[gk] = check
+	}
+	return r
+}
+
+// Register adds or replaces the health check for the given group kind
+func (r *Registry) Register(gk schema.GroupKind, check HealthCheck) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checks[gk] = check
+}
+
+// Unregister removes the health check for the given group kind
+func (r *Registry) Unregister(gk schema.GroupKind) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.checks, gk)
+}
+
+// Get returns the health check for the given group kind or nil if there is none
+func (r *Registry) Get(gk schema.GroupKind) HealthCheck {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.checks[gk]
+}
+
+// GetResourceHealth returns the health of obj. Objects that are being deleted are Progressing, unless they are
+// hooks. The override, which may be nil, is consulted before the registered health checks. If neither knows
+// the kind of obj, nil is returned. If a health check fails, the status is Unknown and the error is returned.
+func (r *Registry) GetResourceHealth(obj *unstructured.Unstructured, override HealthOverride) (*v1alpha1.HealthStatus, error) {
+	if obj.GetDeletionTimestamp() != nil && !slices.Contains(obj.GetFinalizers(), hookFinalizer) {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Pending deletion"}, nil
+	}
+
+	if override != nil {
+		health, err := override.GetResourceHealth(obj)
+		if err != nil {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusUnknown, Message: err.Error()}, err
+		}
+		if health != nil {
+			return health, nil
+		}
+	}
+
+	check := r.Get(obj.GroupVersionKind().GroupKind())
+	if check == nil {
+		return nil, nil
+	}
+	health, err := check(obj)
+	if err != nil {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusUnknown, Message: err.Error()}, err
+	}
+	return health, nil
+}
+
+// GetResourceHealth returns the health of obj using the DefaultRegistry
+func GetResourceHealth(obj *unstructured.Unstructured, override HealthOverride) (*v1alpha1.HealthStatus, error) {
+	return DefaultRegistry.GetResourceHealth(obj, override)
+}
+
+// builtinChecks are the health checks Argo CD ships for core Kubernetes kinds
+var builtinChecks = map[schema.GroupKind]HealthCheck{
+	{Group: "apps", Kind: "Deployment"}:                     getDeploymentHealth,
+	{Group: "apps", Kind: "StatefulSet"}:                    getStatefulSetHealth,
+	{Group: "apps", Kind: "DaemonSet"}:                      getDaemonSetHealth,
+	{Group: "apps", Kind: "ReplicaSet"}:                     getReplicaSetHealth,
+	{Group: "", Kind: "Pod"}:                                getPodHealth,
+	{Group: "", Kind: "PersistentVolumeClaim"}:              getPVCHealth,
+	{Group: "", Kind: "Service"}:                            getServiceHealth,
+	{Group: "batch", Kind: "Job"}:                           getJobHealth,
+	{Group: "extensions", Kind: "Ingress"}:                  getIngressHealth,
+	{Group: "networking.k8s.io", Kind: "Ingress"}:           getIngressHealth,
+	{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}: getHPAHealth,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:   getAPIServiceHealth,
+}
+
+// fromUnstructured converts obj into the typed object into, if obj has the expected group version kind
+func fromUnstructured(obj *unstructured.Unstructured, gvk schema.GroupVersionKind, into any) error {
+	if obj.GroupVersionKind() != gvk {
+		return fmt.Errorf("unsupported %s GVK: %s", gvk.Kind, obj.GroupVersionKind())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into); err != nil {
+		return fmt.Errorf("failed to convert unstructured %s to typed: %w", gvk.Kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getAPIServiceHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	switch obj.GroupVersionKind().Version {
+	case "v1", "v1beta1":
+	default:
+		return nil, fmt.Errorf("unsupported APIService GVK: %s", obj.GroupVersionKind())
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok || cond["type"] != "Available" {
+			continue
+		}
+		reason, _ := cond["reason"].(string)
+		msg, _ := cond["message"].(string)
+		message := fmt.Sprintf("%s: %s", reason, msg)
+		if cond["status"] == "True" {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: message}, nil
+		}
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: message}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Waiting to be processed"}, nil
+}
@@ -0,0 +1,31 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getDaemonSetHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var daemon appsv1.DaemonSet
+	if err := fromUnstructured(obj, appsv1.SchemeGroupVersion.WithKind("DaemonSet"), &daemon); err != nil {
+		return nil, err
+	}
+
+	// Borrowed at kubernetes/kubectl/rollout_status.go
+	if daemon.Generation > daemon.Status.ObservedGeneration {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Waiting for rollout to finish: observed daemon set generation less than desired generation"}, nil
+	}
+	if daemon.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: fmt.Sprintf("daemon set %d out of %d new pods have been updated", daemon.Status.UpdatedNumberScheduled, daemon.Status.DesiredNumberScheduled)}, nil
+	}
+	if daemon.Status.UpdatedNumberScheduled < daemon.Status.DesiredNumberScheduled {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...", daemon.Name, daemon.Status.UpdatedNumberScheduled, daemon.Status.DesiredNumberScheduled)}, nil
+	}
+	if daemon.Status.NumberAvailable < daemon.Status.DesiredNumberScheduled {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...", daemon.Name, daemon.Status.NumberAvailable, daemon.Status.DesiredNumberScheduled)}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy}, nil
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getDeploymentHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var deployment appsv1.Deployment
+	if err := fromUnstructured(obj, appsv1.SchemeGroupVersion.WithKind("Deployment"), &deployment); err != nil {
+		return nil, err
+	}
+
+	if deployment.Spec.Paused {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusSuspended, Message: "Deployment is paused"}, nil
+	}
+	// Borrowed at kubernetes/kubectl/rollout_status.go
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Waiting for rollout to finish: observed deployment generation less than desired generation"}, nil
+	}
+
+	cond := getDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+	switch {
+	case cond != nil && cond.Reason == "ProgressDeadlineExceeded":
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: fmt.Sprintf("Deployment %q exceeded its progress deadline", deployment.Name)}, nil
+	case deployment.Spec.Replicas != nil && deployment.Status.UpdatedReplicas < *deployment.Spec.Replicas:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d out of %d new replicas have been updated...", deployment.Status.UpdatedReplicas, *deployment.Spec.Replicas)}, nil
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d old replicas are pending termination...", deployment.Status.Replicas-deployment.Status.UpdatedReplicas)}, nil
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d of %d updated replicas are available...", deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy}, nil
+}
+
+func getDeploymentCondition(status appsv1.DeploymentStatus, condType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// hpaConditionsAnnotation holds the conditions of autoscaling/v1 HPAs, which have no conditions field
+const hpaConditionsAnnotation = "autoscaling.alpha.kubernetes.io/conditions"
+
+type hpaCondition struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Status  string `json:"status"`
+}
+
+var (
+	// hpaDegradedStates are the condition type and reason combinations that indicate a degraded HPA
+	hpaDegradedStates = []hpaCondition{
+		{Type: "AbleToScale", Reason: "FailedGetScale"},
+		{Type: "AbleToScale", Reason: "FailedUpdateScale"},
+		{Type: "ScalingActive", Reason: "FailedGetResourceMetric"},
+		{Type: "ScalingActive", Reason: "InvalidSelector"},
+	}
+	// hpaHealthyConditionTypes are the condition types that indicate a healthy HPA if true
+	hpaHealthyConditionTypes = []string{"AbleToScale", "ScalingLimited"}
+)
+
+func getHPAHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var conditions []hpaCondition
+	switch obj.GroupVersionKind().Version {
+	case "v1":
+		annotation, ok := obj.GetAnnotations()[hpaConditionsAnnotation]
+		if !ok {
+			return hpaProgressingStatus(), nil
+		}
+		if err := json.Unmarshal([]byte(annotation), &conditions); err != nil {
+			return nil, fmt.Errorf("failed to convert conditions annotation to typed: %w", err)
+		}
+	case "v2", "v2beta1", "v2beta2":
+		raw, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured HPA to typed: %w", err)
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &conditions); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured HPA to typed: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported HPA GVK: %s", obj.GroupVersionKind())
+	}
+
+	for _, condition := range conditions {
+		if isHPADegraded(condition) {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: condition.Message}, nil
+		}
+		if isHPAHealthy(condition) {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: condition.Message}, nil
+		}
+	}
+	return hpaProgressingStatus(), nil
+}
+
+func hpaProgressingStatus() *v1alpha1.HealthStatus {
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Waiting to Autoscale"}
+}
+
+func isHPADegraded(condition hpaCondition) bool {
+	for _, state := range hpaDegradedStates {
+		if condition.Type == state.Type && condition.Reason == state.Reason {
+			return true
+		}
+	}
+	return false
+}
+
+func isHPAHealthy(condition hpaCondition) bool {
+	for _, conditionType := range hpaHealthyConditionTypes {
+		if condition.Type == conditionType && condition.Status == "True" {
+			return true
+		}
+	}
+	return false
+}
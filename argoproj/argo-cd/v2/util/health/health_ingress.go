@@ -0,0 +1,14 @@
+package health
+
+import (
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getIngressHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	ingresses, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	if len(ingresses) == 0 {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy}, nil
+}
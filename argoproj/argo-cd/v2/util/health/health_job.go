@@ -0,0 +1,49 @@
+package health
+
+import (
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getJobHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var job batchv1.Job
+	if err := fromUnstructured(obj, batchv1.SchemeGroupVersion.WithKind("Job"), &job); err != nil {
+		return nil, err
+	}
+
+	failed := false
+	var failMsg string
+	complete := false
+	var message string
+	isSuspended := false
+	for _, condition := range job.Status.Conditions {
+		switch condition.Type {
+		case batchv1.JobFailed:
+			failed = true
+			complete = true
+			failMsg = condition.Message
+		case batchv1.JobComplete:
+			complete = true
+			message = condition.Message
+		case batchv1.JobSuspended:
+			complete = true
+			message = condition.Message
+			if condition.Status == corev1.ConditionTrue {
+				isSuspended = true
+			}
+		}
+	}
+
+	switch {
+	case !complete:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: message}, nil
+	case failed:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: failMsg}, nil
+	case isSuspended:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusSuspended, Message: message}, nil
+	default:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: message}, nil
+	}
+}
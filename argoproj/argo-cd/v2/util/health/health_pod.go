@@ -0,0 +1,95 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getPodHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var pod corev1.Pod
+	if err := fromUnstructured(obj, corev1.SchemeGroupVersion.WithKind("Pod"), &pod); err != nil {
+		return nil, err
+	}
+
+	// This logic cannot be applied when the restart policy is OnFailure or Never, otherwise it breaks the resource
+	// hook logic: a hook pod with ImagePullBackOff would prematurely fail the hook, and still be executed once the
+	// error is resolved even though the sync has completed.
+	if pod.Spec.RestartPolicy == corev1.RestartPolicyAlways {
+		var messages []string
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			waiting := containerStatus.State.Waiting
+			if waiting != nil && (strings.HasPrefix(waiting.Reason, "Err") || strings.HasSuffix(waiting.Reason, "Error") || strings.HasSuffix(waiting.Reason, "BackOff")) {
+				messages = append(messages, waiting.Message)
+			}
+		}
+		if len(messages) > 0 {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: strings.Join(messages, ", ")}, nil
+		}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodPending:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: pod.Status.Message}, nil
+	case corev1.PodSucceeded:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: pod.Status.Message}, nil
+	case corev1.PodFailed:
+		if pod.Status.Message != "" {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: pod.Status.Message}, nil
+		}
+		for _, ctr := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if msg := getContainerFailMessage(ctr); msg != "" {
+				return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: msg}, nil
+			}
+		}
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded}, nil
+	case corev1.PodRunning:
+		switch pod.Spec.RestartPolicy {
+		case corev1.RestartPolicyAlways:
+			// if pod is ready, it is automatically healthy
+			if isPodReady(&pod) {
+				return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: pod.Status.Message}, nil
+			}
+			// if it's not ready, check to see if any container terminated, if so, it's degraded
+			for _, ctrStatus := range pod.Status.ContainerStatuses {
+				if ctrStatus.LastTerminationState.Terminated != nil {
+					return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: pod.Status.Message}, nil
+				}
+			}
+			// otherwise we are progressing towards a ready state
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: pod.Status.Message}, nil
+		case corev1.RestartPolicyOnFailure, corev1.RestartPolicyNever:
+			// pods with a restart policy of OnFailure or Never have a finite life and are typically resource
+			// hooks, so they are considered Progressing instead of Healthy
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: pod.Status.Message}, nil
+		}
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusUnknown, Message: pod.Status.Message}, nil
+}
+
+func getContainerFailMessage(ctr corev1.ContainerStatus) string {
+	terminated := ctr.State.Terminated
+	switch {
+	case terminated == nil:
+		return ""
+	case terminated.Message != "":
+		return terminated.Message
+	case terminated.Reason == "OOMKilled":
+		return terminated.Reason
+	case terminated.ExitCode != 0:
+		return fmt.Sprintf("container %q failed with exit code %d", ctr.Name, terminated.ExitCode)
+	}
+	return ""
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getPVCHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := fromUnstructured(obj, corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), &pvc); err != nil {
+		return nil, err
+	}
+
+	switch pvc.Status.Phase {
+	case corev1.ClaimLost:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded}, nil
+	case corev1.ClaimPending:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing}, nil
+	case corev1.ClaimBound:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy}, nil
+	default:
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusUnknown, Message: fmt.Sprintf("unknown PVC phase %q", pvc.Status.Phase)}, nil
+	}
+}
@@ -0,0 +1,30 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getReplicaSetHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var replicaSet appsv1.ReplicaSet
+	if err := fromUnstructured(obj, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), &replicaSet); err != nil {
+		return nil, err
+	}
+
+	if replicaSet.Generation > replicaSet.Status.ObservedGeneration {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Waiting for rollout to finish: observed replica set generation less than desired generation"}, nil
+	}
+	for _, cond := range replicaSet.Status.Conditions {
+		if cond.Type == appsv1.ReplicaSetReplicaFailure && cond.Status == corev1.ConditionTrue {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusDegraded, Message: cond.Message}, nil
+		}
+	}
+	if replicaSet.Spec.Replicas != nil && replicaSet.Status.AvailableReplicas < *replicaSet.Spec.Replicas {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d out of %d new replicas are available...", replicaSet.Status.AvailableReplicas, *replicaSet.Spec.Replicas)}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy}, nil
+}
@@ -0,0 +1,19 @@
+package health
+
+import (
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getServiceHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var service corev1.Service
+	if err := fromUnstructured(obj, corev1.SchemeGroupVersion.WithKind("Service"), &service); err != nil {
+		return nil, err
+	}
+
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer && len(service.Status.LoadBalancer.Ingress) == 0 {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy}, nil
+}
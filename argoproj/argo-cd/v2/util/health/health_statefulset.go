@@ -0,0 +1,39 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func getStatefulSetHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	var sts appsv1.StatefulSet
+	if err := fromUnstructured(obj, appsv1.SchemeGroupVersion.WithKind("StatefulSet"), &sts); err != nil {
+		return nil, err
+	}
+
+	// Borrowed at kubernetes/kubectl/rollout_status.go
+	if sts.Status.ObservedGeneration == 0 || sts.Generation > sts.Status.ObservedGeneration {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: "Waiting for statefulset spec update to be observed..."}, nil
+	}
+	if sts.Spec.Replicas != nil && sts.Status.ReadyReplicas < *sts.Spec.Replicas {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for %d pods to be ready...", *sts.Spec.Replicas-sts.Status.ReadyReplicas)}, nil
+	}
+	if sts.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType && sts.Spec.UpdateStrategy.RollingUpdate != nil {
+		if sts.Spec.Replicas != nil && sts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+			if expected := *sts.Spec.Replicas - *sts.Spec.UpdateStrategy.RollingUpdate.Partition; sts.Status.UpdatedReplicas < expected {
+				return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...", sts.Status.UpdatedReplicas, expected)}, nil
+			}
+		}
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", sts.Status.UpdatedReplicas)}, nil
+	}
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: fmt.Sprintf("statefulset has %d ready pods", sts.Status.ReadyReplicas)}, nil
+	}
+	if sts.Status.UpdateRevision != sts.Status.CurrentRevision {
+		return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusProgressing, Message: fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...", sts.Status.UpdatedReplicas, sts.Status.UpdateRevision)}, nil
+	}
+	return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusHealthy, Message: fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...", sts.Status.CurrentReplicas, sts.Status.CurrentRevision)}, nil
+}
@@ -0,0 +1,190 @@
+package health
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// healthCase is a fixture in testdata, ported from the health checks of gitops-engine, and its expected health
+type healthCase struct {
+	file    string
+	status  v1alpha1.HealthStatusCode
+	message string
+}
+
+func loadFixture(t *testing.T, file string) *unstructured.Unstructured {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func assertHealth(t *testing.T, cases []healthCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(strings.TrimSuffix(tc.file, ".yaml"), func(t *testing.T) {
+			health, err := GetResourceHealth(loadFixture(t, tc.file), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if health == nil {
+				t.Fatal("expected a health status")
+			}
+			if health.Status != tc.status {
+				t.Errorf("expected status %s, got %s: %s", tc.status, health.Status, health.Message)
+			}
+			if tc.message != "" && health.Message != tc.message {
+				t.Errorf("expected message %q, got %q", tc.message, health.Message)
+			}
+		})
+	}
+}
+
+func TestDeploymentHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "deployment-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "deployment-progressing.yaml", status: v1alpha1.HealthStatusProgressing, message: "Waiting for rollout to finish: 1 old replicas are pending termination..."},
+		{file: "deployment-generation-not-observed.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "deployment-degraded.yaml", status: v1alpha1.HealthStatusDegraded, message: `Deployment "guestbook-ui" exceeded its progress deadline`},
+		{file: "deployment-suspended.yaml", status: v1alpha1.HealthStatusSuspended},
+	})
+}
+
+func TestStatefulSetHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "statefulset-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "statefulset-progressing.yaml", status: v1alpha1.HealthStatusProgressing, message: "Waiting for 2 pods to be ready..."},
+		{file: "statefulset-partitioned-progressing.yaml", status: v1alpha1.HealthStatusProgressing, message: "Waiting for partitioned roll out to finish: 1 out of 2 new pods have been updated..."},
+	})
+}
+
+func TestDaemonSetHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "daemonset-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "daemonset-progressing.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "daemonset-ondelete.yaml", status: v1alpha1.HealthStatusHealthy},
+	})
+}
+
+func TestReplicaSetHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "replicaset-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "replicaset-progressing.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "replicaset-degraded.yaml", status: v1alpha1.HealthStatusDegraded},
+	})
+}
+
+func TestPodHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "pod-pending.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "pod-running-restart-always.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "pod-running-not-ready.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "pod-running-restart-never.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "pod-crashloop.yaml", status: v1alpha1.HealthStatusDegraded, message: "back-off 5m0s restarting failed container=guestbook-ui"},
+		{file: "pod-imagepullbackoff.yaml", status: v1alpha1.HealthStatusDegraded},
+		{file: "pod-error.yaml", status: v1alpha1.HealthStatusDegraded, message: "container restarted"},
+		{file: "pod-failed.yaml", status: v1alpha1.HealthStatusDegraded, message: `container "migrate" failed with exit code 2`},
+		{file: "pod-succeeded.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "pod-deletion.yaml", status: v1alpha1.HealthStatusProgressing, message: "Pending deletion"},
+		{file: "pod-hook-deletion.yaml", status: v1alpha1.HealthStatusHealthy},
+	})
+}
+
+func TestJobHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "job-running.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "job-succeeded.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "job-failed.yaml", status: v1alpha1.HealthStatusDegraded, message: "Job has reached the specified backoff limit"},
+		{file: "job-suspended.yaml", status: v1alpha1.HealthStatusSuspended, message: "Job suspended"},
+	})
+}
+
+func TestHPAHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "hpa-v1-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "hpa-v1-progressing.yaml", status: v1alpha1.HealthStatusProgressing, message: "Waiting to Autoscale"},
+		{file: "hpa-v1-degraded.yaml", status: v1alpha1.HealthStatusDegraded},
+		{file: "hpa-v2-healthy.yaml", status: v1alpha1.HealthStatusHealthy, message: "recommended size matches current size"},
+		{file: "hpa-v2-degraded.yaml", status: v1alpha1.HealthStatusDegraded},
+		{file: "hpa-v2-progressing.yaml", status: v1alpha1.HealthStatusProgressing},
+	})
+}
+
+func TestPVCHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "pvc-bound.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "pvc-pending.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "pvc-lost.yaml", status: v1alpha1.HealthStatusDegraded},
+	})
+}
+
+func TestServiceHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "svc-clusterip.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "svc-loadbalancer-progressing.yaml", status: v1alpha1.HealthStatusProgressing},
+		{file: "svc-loadbalancer-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+	})
+}
+
+func TestIngressHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "ingress-healthy.yaml", status: v1alpha1.HealthStatusHealthy},
+		{file: "ingress-progressing.yaml", status: v1alpha1.HealthStatusProgressing},
+	})
+}
+
+func TestAPIServiceHealth(t *testing.T) {
+	assertHealth(t, []healthCase{
+		{file: "apiservice-v1-true.yaml", status: v1alpha1.HealthStatusHealthy, message: "Passed: all checks passed"},
+		{file: "apiservice-v1-false.yaml", status: v1alpha1.HealthStatusProgressing, message: "FailedDiscoveryCheck: failing or missing response"},
+	})
+}
+
+// healthOverride returns a fixed health for all objects
+type healthOverride v1alpha1.HealthStatus
+
+func (o *healthOverride) GetResourceHealth(*unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	return (*v1alpha1.HealthStatus)(o), nil
+}
+
+func TestGetResourceHealthOverride(t *testing.T) {
+	health, err := GetResourceHealth(loadFixture(t, "pod-crashloop.yaml"), &healthOverride{Status: v1alpha1.HealthStatusHealthy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != v1alpha1.HealthStatusHealthy {
+		t.Errorf("expected the override to take precedence, got %s", health.Status)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("example.com/v1")
+	obj.SetKind("Unknown")
+	if health, err := GetResourceHealth(obj, nil); err != nil || health != nil {
+		t.Errorf("expected no health for an unknown kind, got %v, %v", health, err)
+	}
+}
+
+func TestGetResourceHealthUnsupportedVersion(t *testing.T) {
+	obj := loadFixture(t, "deployment-healthy.yaml")
+	obj.SetAPIVersion("apps/v1beta1")
+	health, err := GetResourceHealth(obj, nil)
+	if err == nil || health == nil || health.Status != v1alpha1.HealthStatusUnknown {
+		t.Errorf("expected an Unknown health and an error, got %v, %v", health, err)
+	}
+}
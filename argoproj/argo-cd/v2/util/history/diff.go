@@ -0,0 +1,136 @@
+// Package history compares the entries of an Application's revision history, for changelogs and audits.
+package history
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// Change is a field of a source that differs between two history entries. Structured fields such as helm
+// are rendered as JSON.
+type Change struct {
+	Field string
+	From  string
+	To    string
+}
+
+// SourceDiff holds the differences of the source at the same position of two history entries
+type SourceDiff struct {
+	// Index is the position of the source, 0 for single source entries
+	Index int
+	// From is the source of the older entry, nil if the source was added
+	From *v1alpha1.ApplicationSource
+	// To is the source of the newer entry, nil if the source was removed
+	To *v1alpha1.ApplicationSource
+	// Changes holds the changed fields, including the deployed revision as field "revision"
+	Changes []Change
+}
+
+// Added returns true if the source only exists in the newer entry
+func (d SourceDiff) Added() bool {
+	return d.From == nil
+}
+
+// Removed returns true if the source only exists in the older entry
+func (d SourceDiff) Removed() bool {
+	return d.To == nil
+}
+
+// Diff holds the differences between two history entries
+type Diff struct {
+	FromID int64
+	ToID   int64
+	// Sources holds the sources that differ, ordered by index
+	Sources []SourceDiff
+}
+
+// IsEmpty returns true if both entries deployed the same sources at the same revisions
+func (d Diff) IsEmpty() bool {
+	return len(d.Sources) == 0
+}
+
+// Compare returns the differences of sources and revisions between from and to. Single and multi-source
+// entries are compared source by source, a single source entry being a multi-source entry with one source.
+func Compare(from, to *v1alpha1.RevisionHistory) Diff {
+	diff := Diff{FromID: from.ID, ToID: to.ID}
+	fromSources, fromRevisions := sources(from)
+	toSources, toRevisions := sources(to)
+	for i := 0; i < max(len(fromSources), len(toSources)); i++ {
+		d := SourceDiff{Index: i}
+		if i < len(fromSources) {
+			d.From = &fromSources[i]
+		}
+		if i < len(toSources) {
+			d.To = &toSources[i]
+		}
+		d.Changes = compareSource(d.From, d.To, at(fromRevisions, i), at(toRevisions, i))
+		if len(d.Changes) > 0 {
+			diff.Sources = append(diff.Sources, d)
+		}
+	}
+	return diff
+}
+
+// sources returns the sources of an entry and the revision of each
+func sources(h *v1alpha1.RevisionHistory) (v1alpha1.ApplicationSources, []string) {
+	if !h.Sources.IsZero() {
+		return h.Sources, h.Revisions
+	}
+	if !h.Source.IsZero() {
+		return v1alpha1.ApplicationSources{h.Source}, []string{h.Revision}
+	}
+	return nil, nil
+}
+
+func at(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+func compareSource(from, to *v1alpha1.ApplicationSource, fromRevision, toRevision string) []Change {
+	if from == nil {
+		from = &v1alpha1.ApplicationSource{}
+	}
+	if to == nil {
+		to = &v1alpha1.ApplicationSource{}
+	}
+
+	var changes []Change
+	add := func(field string, a, b any) {
+		if reflect.DeepEqual(a, b) {
+			return
+		}
+		changes = append(changes, Change{Field: field, From: render(a), To: render(b)})
+	}
+	add("repoURL", from.RepoURL, to.RepoURL)
+	add("path", from.Path, to.Path)
+	add("chart", from.Chart, to.Chart)
+	add("targetRevision", from.TargetRevision, to.TargetRevision)
+	add("ref", from.Ref, to.Ref)
+	add("name", from.Name, to.Name)
+	add("helm", from.Helm, to.Helm)
+	add("kustomize", from.Kustomize, to.Kustomize)
+	add("directory", from.Directory, to.Directory)
+	add("plugin", from.Plugin, to.Plugin)
+	add("revision", fromRevision, toRevision)
+	return changes
+}
+
+// render formats strings as they are and other values as JSON, with nil values rendered as empty string
+func render(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,145 @@
+package lua
+
+import (
+	"math"
+	"sort"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// toLua converts an unstructured value into a Lua value
+func toLua(l *lua.LState, value any) lua.LValue {
+	switch v := value.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case string:
+		return lua.LString(v)
+	case int:
+		return lua.LNumber(v)
+	case int32:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case float64:
+		return lua.LNumber(v)
+	case map[string]any:
+		table := l.CreateTable(0, len(v))
+		for key, item := range v {
+			table.RawSetString(key, toLua(l, item))
+		}
+		return table
+	case []any:
+		table := l.CreateTable(len(v), 0)
+		for _, item := range v {
+			table.Append(toLua(l, item))
+		}
+		return table
+	default:
+		return lua.LNil
+	}
+}
+
+// fromLua converts a Lua value into an unstructured value. Tables with consecutive integer keys starting at
+// 1 become lists, empty tables become empty lists and all other tables become maps. Integral numbers become
+// int64.
+func fromLua(value lua.LValue) any {
+	switch v := value.(type) {
+	case *lua.LNilType:
+		return nil
+	case lua.LBool:
+		return bool(v)
+	case lua.LString:
+		return string(v)
+	case lua.LNumber:
+		f := float64(v)
+		if f == math.Trunc(f) && f >= math.MinInt64 && f <= math.MaxInt64 {
+			return int64(f)
+		}
+		return f
+	case *lua.LTable:
+		if n := v.MaxN(); n > 0 && n == countKeys(v) {
+			list := make([]any, 0, n)
+			for i := 1; i <= n; i++ {
+				list = append(list, fromLua(v.RawGetInt(i)))
+			}
+			return list
+		}
+		if countKeys(v) == 0 {
+			return []any{}
+		}
+		m := map[string]any{}
+		v.ForEach(func(key, item lua.LValue) {
+			m[key.String()] = fromLua(item)
+		})
+		return m
+	default:
+		return nil
+	}
+}
+
+func countKeys(table *lua.LTable) int {
+	n := 0
+	table.ForEach(func(lua.LValue, lua.LValue) {
+		n++
+	})
+	return n
+}
+
+// cleanReturnedObj restores empty maps of the original object that the conversion from Lua turned into empty
+// lists, as Lua cannot distinguish an empty table as list or map
+func cleanReturnedObj(newObj, obj map[string]any) map[string]any {
+	for key, oldValue := range obj {
+		newValue, ok := newObj[key]
+		if !ok {
+			continue
+		}
+		switch nv := newValue.(type) {
+		case map[string]any:
+			if ov, ok := oldValue.(map[string]any); ok {
+				newObj[key] = cleanReturnedObj(nv, ov)
+			}
+		case []any:
+			switch ov := oldValue.(type) {
+			case map[string]any:
+				if len(nv) == 0 {
+					newObj[key] = map[string]any{}
+				}
+			case []any:
+				newObj[key] = cleanReturnedArray(nv, ov)
+			}
+		}
+	}
+	return newObj
+}
+
+func cleanReturnedArray(newArray, array []any) []any {
+	for i := range newArray {
+		if i >= len(array) {
+			break
+		}
+		switch nv := newArray[i].(type) {
+		case map[string]any:
+			if ov, ok := array[i].(map[string]any); ok {
+				newArray[i] = cleanReturnedObj(nv, ov)
+			}
+		case []any:
+			if ov, ok := array[i].([]any); ok {
+				newArray[i] = cleanReturnedArray(nv, ov)
+			}
+		}
+	}
+	return newArray
+}
+
+func sortedActions(actions map[string]ResourceAction) []ResourceAction {
+	result := make([]ResourceAction, 0, len(actions))
+	for _, action := range actions {
+		result = append(result, action)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
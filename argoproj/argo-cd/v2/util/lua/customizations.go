@@ -0,0 +1,136 @@
+package lua
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	resourceCustomizationsKey       = "resource.customizations"
+	resourceCustomizationsHealthKey = "resource.customizations.health."
+	resourceCustomizationsActionKey = "resource.customizations.actions."
+)
+
+// ResourceCustomization holds the Lua scripts configured for a resource kind
+type ResourceCustomization struct {
+	// HealthLua is the health check script
+	HealthLua string
+	// Actions holds the custom actions
+	Actions ResourceActions
+}
+
+// ResourceActions holds the action discovery script and the custom actions of a resource kind
+type ResourceActions struct {
+	// ActionDiscoveryLua is the script that reports the available actions
+	ActionDiscoveryLua string `json:"discovery.lua,omitempty"`
+	// Definitions are the available actions
+	Definitions []ResourceActionDefinition `json:"definitions,omitempty"`
+}
+
+// ResourceActionDefinition is a single custom action
+type ResourceActionDefinition struct {
+	// Name is the name of the action
+	Name string `json:"name"`
+	// ActionLua is the script performing the action
+	ActionLua string `json:"action.lua"`
+}
+
+// ParseResourceCustomizations reads the Lua customizations from the data of the argocd-cm ConfigMap. Both the
+// resource.customizations.health.<group_kind> and resource.customizations.actions.<group_kind> keys and the
+// deprecated resource.customizations block are supported, the former taking precedence. The result is keyed
+// by group/kind, or kind for the core group, and keys may contain glob patterns.
+func ParseResourceCustomizations(data map[string]string) (map[string]ResourceCustomization, error) {
+	customizations := map[string]ResourceCustomization{}
+
+	if legacy, ok := data[resourceCustomizationsKey]; ok {
+		overrides := map[string]struct {
+			HealthLua string `json:"health.lua,omitempty"`
+			Actions   string `json:"actions,omitempty"`
+		}{}
+		if err := yaml.Unmarshal([]byte(legacy), &overrides); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", resourceCustomizationsKey, err)
+		}
+		for key, override := range overrides {
+			customization := ResourceCustomization{HealthLua: override.HealthLua}
+			if override.Actions != "" {
+				if err := yaml.Unmarshal([]byte(override.Actions), &customization.Actions); err != nil {
+					return nil, fmt.Errorf("invalid actions of %s in %s: %w", key, resourceCustomizationsKey, err)
+				}
+			}
+			customizations[key] = customization
+		}
+	}
+
+	for key, value := range data {
+		switch {
+		case strings.HasPrefix(key, resourceCustomizationsHealthKey):
+			overrideKey, err := convertToOverrideKey(strings.TrimPrefix(key, resourceCustomizationsHealthKey))
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %s: %w", key, err)
+			}
+			customization := customizations[overrideKey]
+			customization.HealthLua = value
+			customizations[overrideKey] = customization
+		case strings.HasPrefix(key, resourceCustomizationsActionKey):
+			overrideKey, err := convertToOverrideKey(strings.TrimPrefix(key, resourceCustomizationsActionKey))
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %s: %w", key, err)
+			}
+			customization := customizations[overrideKey]
+			customization.Actions = ResourceActions{}
+			if err := yaml.Unmarshal([]byte(value), &customization.Actions); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			customizations[overrideKey] = customization
+		}
+	}
+	return customizations, nil
+}
+
+// OverrideKey returns the key of the customizations of the given group kind
+func OverrideKey(gk schema.GroupKind) string {
+	if gk.Group == "" {
+		return gk.Kind
+	}
+	return gk.Group + "/" + gk.Kind
+}
+
+// convertToOverrideKey converts the <group_kind> suffix of a ConfigMap key to group/kind
+func convertToOverrideKey(groupKind string) (string, error) {
+	parts := strings.Split(groupKind, "_")
+	switch {
+	case len(parts) == 2:
+		return parts[0] + "/" + parts[1], nil
+	case len(parts) == 1 && groupKind != "":
+		return groupKind, nil
+	}
+	return "", fmt.Errorf("group kind should be in format <group_kind> or <kind>, got %q", groupKind)
+}
+
+// getCustomization returns the customization of obj. Exact keys take precedence over glob patterns.
+func (vm *VM) getCustomization(obj *unstructured.Unstructured) (ResourceCustomization, bool) {
+	key := OverrideKey(obj.GroupVersionKind().GroupKind())
+	if customization, ok := vm.ResourceCustomizations[key]; ok {
+		return customization, true
+	}
+
+	patterns := make([]string, 0, len(vm.ResourceCustomizations))
+	for pattern := range vm.ResourceCustomizations {
+		if strings.ContainsAny(pattern, "*?[{") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if g, err := glob.Compile(pattern); err == nil && g.Match(key) {
+			return vm.ResourceCustomizations[pattern], true
+		}
+	}
+	return ResourceCustomization{}, false
+}
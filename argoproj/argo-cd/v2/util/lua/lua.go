@@ -0,0 +1,290 @@
+// Package lua runs the custom health checks and actions Argo CD operators define in Lua through the
+// resource.customizations settings, in a sandboxed and bounded Lua VM.
+package lua
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	lua "github.com/yuin/gopher-lua"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DefaultTimeout is the default time a single script may run, as in Argo CD
+	DefaultTimeout = 1 * time.Second
+	// DefaultCallStackSize is the default maximum depth of nested function calls of a script
+	DefaultCallStackSize = 256
+	// DefaultRegistryMaxSize is the default maximum number of values on the data stack of a script
+	DefaultRegistryMaxSize = 256 * 1024
+	// DefaultMemoryLimit is the default number of bytes a script may create with string.rep
+	DefaultMemoryLimit = 64 * 1024 * 1024
+
+	invalidHealthStatus = "Lua returned an invalid health status"
+)
+
+var (
+	// ErrTimeout is returned when a script exceeds the execution timeout
+	ErrTimeout = errors.New("lua script execution timed out")
+	// ErrMemoryLimit is returned when a script exceeds the memory limit
+	ErrMemoryLimit = errors.New("lua script exceeded the memory limit")
+)
+
+// K8SOperation is the operation an action requests for an impacted resource
+type K8SOperation string
+
+const (
+	// CreateOperation requests the creation of a new resource
+	CreateOperation K8SOperation = "create"
+	// PatchOperation requests patching an existing resource
+	PatchOperation K8SOperation = "patch"
+)
+
+// ImpactedResource is a resource returned by an action
+type ImpactedResource struct {
+	// UnstructuredObj is the resulting object
+	UnstructuredObj *unstructured.Unstructured `json:"resource"`
+	// K8SOperation is the operation to perform with the object
+	K8SOperation K8SOperation `json:"operation"`
+}
+
+// ResourceAction is an action returned by an action discovery script
+type ResourceAction struct {
+	// Name is the name of the action
+	Name string `json:"name"`
+	// Disabled is true if the action is currently not available for the resource
+	Disabled bool `json:"disabled,omitempty"`
+	// IconClass is the CSS class of the icon shown for the action
+	IconClass string `json:"iconClass,omitempty"`
+	// DisplayName is the human-readable name of the action
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// VM evaluates Lua health checks and actions. Scripts run in a fresh state with only the base, package,
+// table, string and math libraries plus a restricted os library that gives access to the clock. The zero
+// value is ready to use and applies the defaults.
+type VM struct {
+	// ResourceCustomizations holds the scripts of resource kinds, keyed by group/kind. See ParseResourceCustomizations.
+	ResourceCustomizations map[string]ResourceCustomization
+	// Timeout is the maximum time a single script may run. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// CallStackSize limits the depth of nested function calls. Defaults to DefaultCallStackSize.
+	CallStackSize int
+	// RegistryMaxSize limits the number of values on the data stack, that is the locals and temporaries of a
+	// script. It does not bound the size of strings and tables. Defaults to DefaultRegistryMaxSize.
+	RegistryMaxSize int
+	// MemoryLimit is the total number of bytes of the strings a script may create with string.rep. Other
+	// allocations of a script are only bounded by Timeout. Defaults to DefaultMemoryLimit.
+	MemoryLimit int64
+}
+
+// ExecuteHealthLua runs a health script against obj. The script reads the object from the global obj and
+// returns a table with status and message. An unknown status results in Unknown, a nil result in an empty
+// health status.
+func (vm *VM) ExecuteHealthLua(obj *unstructured.Unstructured, script string) (*v1alpha1.HealthStatus, error) {
+	l, err := vm.run(obj, script)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	returnValue := l.Get(-1)
+	switch returnValue.Type() {
+	case lua.LTNil:
+		return &v1alpha1.HealthStatus{}, nil
+	case lua.LTTable:
+		data, err := json.Marshal(fromLua(returnValue))
+		if err != nil {
+			return nil, err
+		}
+		health := &v1alpha1.HealthStatus{}
+		if err := json.Unmarshal(data, health); err != nil {
+			return nil, fmt.Errorf("lua returned an invalid health status: %w", err)
+		}
+		if !isValidHealthStatusCode(health.Status) {
+			return &v1alpha1.HealthStatus{Status: v1alpha1.HealthStatusUnknown, Message: invalidHealthStatus}, nil
+		}
+		return health, nil
+	default:
+		return nil, fmt.Errorf("expect table output from Lua script, not %s", returnValue.Type())
+	}
+}
+
+// ExecuteResourceActionDiscovery runs action discovery scripts against obj and returns the actions they
+// report, sorted by name. Each script returns a table mapping action names to tables with optional
+// disabled, iconClass and displayName fields.
+func (vm *VM) ExecuteResourceActionDiscovery(obj *unstructured.Unstructured, scripts ...string) ([]ResourceAction, error) {
+	actions := map[string]ResourceAction{}
+	for _, script := range scripts {
+		l, err := vm.run(obj, script)
+		if err != nil {
+			return nil, err
+		}
+		returnValue := l.Get(-1)
+		l.Close()
+		if returnValue.Type() != lua.LTTable {
+			return nil, fmt.Errorf("expect table output from Lua script, not %s", returnValue.Type())
+		}
+
+		discovered, ok := fromLua(returnValue).(map[string]any)
+		if !ok {
+			// an empty table is decoded as list
+			continue
+		}
+		for name, value := range discovered {
+			action := ResourceAction{}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, &action); err != nil {
+				return nil, fmt.Errorf("lua returned an invalid action %q: %w", name, err)
+			}
+			action.Name = name
+			actions[name] = action
+		}
+	}
+	return sortedActions(actions), nil
+}
+
+// ExecuteResourceAction runs an action script against obj and returns the resources it impacts. A script
+// either returns the modified object, which is treated as a patch, or a list of tables with resource and
+// operation fields. Empty tables of patched objects keep their original map or list type, as Lua cannot
+// distinguish them.
+func (vm *VM) ExecuteResourceAction(obj *unstructured.Unstructured, script string) ([]ImpactedResource, error) {
+	l, err := vm.run(obj, script)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	returnValue := l.Get(-1)
+	if returnValue.Type() != lua.LTTable {
+		return nil, fmt.Errorf("expect table output from Lua script, not %s", returnValue.Type())
+	}
+
+	var impacted []ImpactedResource
+	switch value := fromLua(returnValue).(type) {
+	case map[string]any:
+		impacted = append(impacted, ImpactedResource{UnstructuredObj: &unstructured.Unstructured{Object: value}, K8SOperation: PatchOperation})
+	case []any:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &impacted); err != nil {
+			return nil, fmt.Errorf("lua returned invalid impacted resources: %w", err)
+		}
+	}
+	if len(impacted) == 0 {
+		return nil, errors.New("lua output was not a valid object or list of impacted resources")
+	}
+
+	for _, resource := range impacted {
+		if resource.UnstructuredObj == nil {
+			return nil, errors.New("lua returned an impacted resource without resource")
+		}
+		switch resource.K8SOperation {
+		case PatchOperation:
+			// cleaning the resource is only relevant to patches
+			resource.UnstructuredObj.Object = cleanReturnedObj(resource.UnstructuredObj.Object, obj.Object)
+		case CreateOperation:
+		default:
+			return nil, fmt.Errorf("unsupported operation %q", resource.K8SOperation)
+		}
+	}
+	return impacted, nil
+}
+
+// GetResourceHealth runs the custom health check of obj, if there is one. It implements health.HealthOverride,
+// so a VM can be passed to health.GetResourceHealth.
+func (vm *VM) GetResourceHealth(obj *unstructured.Unstructured) (*v1alpha1.HealthStatus, error) {
+	customization, ok := vm.getCustomization(obj)
+	if !ok || customization.HealthLua == "" {
+		return nil, nil
+	}
+	return vm.ExecuteHealthLua(obj, customization.HealthLua)
+}
+
+// GetResourceActions runs the action discovery script of obj, if there is one, and returns the available actions
+func (vm *VM) GetResourceActions(obj *unstructured.Unstructured) ([]ResourceAction, error) {
+	customization, ok := vm.getCustomization(obj)
+	if !ok || customization.Actions.ActionDiscoveryLua == "" {
+		return nil, nil
+	}
+	return vm.ExecuteResourceActionDiscovery(obj, customization.Actions.ActionDiscoveryLua)
+}
+
+// RunResourceAction runs the named custom action of obj
+func (vm *VM) RunResourceAction(obj *unstructured.Unstructured, action string) ([]ImpactedResource, error) {
+	customization, _ := vm.getCustomization(obj)
+	for _, definition := range customization.Actions.Definitions {
+		if definition.Name == action {
+			return vm.ExecuteResourceAction(obj, definition.ActionLua)
+		}
+	}
+	return nil, fmt.Errorf("action %q is not defined for %s", action, obj.GroupVersionKind().GroupKind())
+}
+
+// run executes script with obj as global and returns the state holding the result on top of the stack. The
+// caller must close the returned state.
+func (vm *VM) run(obj *unstructured.Unstructured, script string) (*lua.LState, error) {
+	l := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   orDefault(vm.CallStackSize, DefaultCallStackSize),
+		RegistryMaxSize: orDefault(vm.RegistryMaxSize, DefaultRegistryMaxSize),
+	})
+	if err := openSafeLibs(l); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	timeout := vm.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	memoryLimit := vm.MemoryLimit
+	if memoryLimit <= 0 {
+		memoryLimit = DefaultMemoryLimit
+	}
+	memoryCtx, cancelMemory := context.WithCancelCause(context.Background())
+	defer cancelMemory(nil)
+	ctx, cancel := context.WithTimeout(memoryCtx, timeout)
+	defer cancel()
+	l.SetContext(ctx)
+	limitStringRep(l, memoryLimit, cancelMemory)
+
+	l.SetGlobal("obj", toLua(l, obj.Object))
+	if err := l.DoString(script); err != nil {
+		l.Close()
+		if errors.Is(context.Cause(memoryCtx), ErrMemoryLimit) {
+			return nil, fmt.Errorf("%w of %d bytes", ErrMemoryLimit, memoryLimit)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %v", ErrTimeout, timeout)
+		}
+		return nil, err
+	}
+	l.RemoveContext()
+	return l, nil
+}
+
+func isValidHealthStatusCode(code v1alpha1.HealthStatusCode) bool {
+	switch code {
+	case v1alpha1.HealthStatusUnknown, v1alpha1.HealthStatusProgressing, v1alpha1.HealthStatusSuspended,
+		v1alpha1.HealthStatusHealthy, v1alpha1.HealthStatusDegraded, v1alpha1.HealthStatusMissing:
+		return true
+	}
+	return false
+}
+
+func orDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
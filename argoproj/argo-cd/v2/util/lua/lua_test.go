@@ -0,0 +1,108 @@
+package lua
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testObject() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "test", "namespace": "default"},
+	}}
+}
+
+func TestExecuteHealthLuaTimeout(t *testing.T) {
+	vm := &VM{Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err := vm.ExecuteHealthLua(testObject(), `while true do end`)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("script was stopped after %v", elapsed)
+	}
+}
+
+func TestExecuteHealthLuaMemoryLimit(t *testing.T) {
+	for name, script := range map[string]string{
+		"growing table": `
+local t = {}
+for i = 1, 1000 do
+  t[i] = string.rep("x", 1e7)
+end
+return {status = "Healthy"}`,
+		"single large string": `
+local s = string.rep("x", 1e10)
+return {status = "Healthy"}`,
+		"string method": `
+local s = ("x"):rep(1e10)
+return {status = "Healthy"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			vm := &VM{Timeout: 10 * time.Second, MemoryLimit: 32 * 1024 * 1024}
+			_, err := vm.ExecuteHealthLua(testObject(), script)
+			if !errors.Is(err, ErrMemoryLimit) {
+				t.Fatalf("expected memory limit error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExecuteHealthLuaCallStackSize(t *testing.T) {
+	vm := &VM{CallStackSize: 64}
+	_, err := vm.ExecuteHealthLua(testObject(), `
+local function depth(n)
+  return depth(n + 1) + 1
+end
+return {status = "Healthy", message = tostring(depth(0))}`)
+	if err == nil || !strings.Contains(err.Error(), "stack overflow") {
+		t.Fatalf("expected stack overflow error, got %v", err)
+	}
+}
+
+func TestExecuteHealthLuaMemoryLimitPerScript(t *testing.T) {
+	// each script may use most of the limit, the limit is not shared between scripts
+	vm := &VM{MemoryLimit: 1024 * 1024}
+	for range 3 {
+		if _, err := vm.ExecuteHealthLua(testObject(), `
+local s = string.rep("x", 1000 * 1000)
+return {status = "Healthy"}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExecuteHealthLuaWithinLimits(t *testing.T) {
+	vm := &VM{}
+	health, err := vm.ExecuteHealthLua(testObject(), `
+local s = string.rep("x", 1000)
+return {status = "Healthy", message = obj.metadata.name}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "Healthy" || health.Message != "test" {
+		t.Errorf("unexpected health %+v", health)
+	}
+}
+
+func TestExecuteResourceActionDiscoveryInvalidAction(t *testing.T) {
+	vm := &VM{}
+	_, err := vm.ExecuteResourceActionDiscovery(testObject(), `return {restart = {disabled = "no"}}`)
+	if err == nil {
+		t.Fatal("expected error for invalid action")
+	}
+
+	actions, err := vm.ExecuteResourceActionDiscovery(testObject(), `return {restart = {disabled = true}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Name != "restart" || !actions[0].Disabled {
+		t.Errorf("unexpected actions %+v", actions)
+	}
+}
@@ -0,0 +1,34 @@
+package lua
+
+import (
+	"context"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// limitStringRep replaces string.rep by a version failing with ErrMemoryLimit once the strings it created in
+// the state would exceed limit bytes in total. string.rep is the only library function that allocates memory
+// in proportion to an argument, so a loop over it can exhaust the memory well before the timeout.
+func limitStringRep(l *lua.LState, limit int64, cancel context.CancelCauseFunc) {
+	str, ok := l.GetGlobal(lua.StringLibName).(*lua.LTable)
+	if !ok {
+		return
+	}
+	remaining := limit
+	str.RawSetString("rep", l.NewFunction(func(l *lua.LState) int {
+		s := l.CheckString(1)
+		n := l.CheckInt(2)
+		if n <= 0 || len(s) == 0 {
+			l.Push(lua.LString(""))
+			return 1
+		}
+		if int64(len(s)) > remaining/int64(n) {
+			cancel(ErrMemoryLimit)
+			l.RaiseError("string.rep result exceeds the memory limit")
+		}
+		remaining -= int64(len(s)) * int64(n)
+		l.Push(lua.LString(strings.Repeat(s, n)))
+		return 1
+	}))
+}
@@ -0,0 +1,46 @@
+package lua
+
+import (
+	lua "github.com/yuin/gopher-lua"
+)
+
+// unsafeOsFunctions are removed from the os library, leaving only clock, date, difftime and time
+var unsafeOsFunctions = []string{"execute", "exit", "getenv", "remove", "rename", "setenv", "setlocale", "tmpname"}
+
+// unsafeBaseFunctions are removed from the base library as they access the file system
+var unsafeBaseFunctions = []string{"dofile", "loadfile"}
+
+// openSafeLibs opens the libraries available to scripts
+func openSafeLibs(l *lua.LState) error {
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.LoadLibName, lua.OpenPackage},
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.OsLibName, lua.OpenOs},
+	} {
+		if err := l.CallByParam(lua.P{Fn: l.NewFunction(lib.open), NRet: 0, Protect: true}, lua.LString(lib.name)); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range unsafeBaseFunctions {
+		l.SetGlobal(name, lua.LNil)
+	}
+	// the os table is shared with package.loaded, so require("os") returns the restricted library as well
+	if os, ok := l.GetGlobal(lua.OsLibName).(*lua.LTable); ok {
+		for _, name := range unsafeOsFunctions {
+			os.RawSetString(name, lua.LNil)
+		}
+	}
+	// only allow requiring the preloaded libraries, not files
+	if pkg, ok := l.GetGlobal(lua.LoadLibName).(*lua.LTable); ok {
+		pkg.RawSetString("path", lua.LString(""))
+		pkg.RawSetString("cpath", lua.LString(""))
+	}
+	return nil
+}
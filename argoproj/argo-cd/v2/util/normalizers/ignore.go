@@ -0,0 +1,161 @@
+// Package normalizers strips the fields an Application's ignoreDifferences rules exclude from comparison,
+// so that live and desired objects can be compared the same way Argo CD does.
+package normalizers
+
+import (
+	"fmt"
+
+	"github.com/gobwas/glob"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MatchedRule is an ignore difference rule that matched a normalized object
+type MatchedRule struct {
+	// Index is the position of the rule in the list the normalizer was created from
+	Index int
+	// Rule is the matching rule
+	Rule v1alpha1.ResourceIgnoreDifferences
+	// Errors holds the errors of jq path expressions of the rule that could not be applied. As in Argo CD, a
+	// failing expression leaves the object unchanged and does not stop the normalization.
+	Errors []error
+}
+
+// IgnoreNormalizer removes the fields that are ignored by a list of ResourceIgnoreDifferences from objects
+type IgnoreNormalizer struct {
+	rules []*ignoreRule
+}
+
+// ignoreRule is a validated and compiled ResourceIgnoreDifferences
+type ignoreRule struct {
+	index    int
+	rule     v1alpha1.ResourceIgnoreDifferences
+	group    glob.Glob
+	kind     glob.Glob
+	pointers []jsonPointer
+	jq       []*jqDeletion
+}
+
+// NewIgnoreNormalizer validates the given rules and returns a normalizer applying them. Group and Kind of a
+// rule support glob patterns, an empty Name or Namespace matches all objects. jq path expressions are
+// evaluated with the default limits.
+func NewIgnoreNormalizer(ignore v1alpha1.IgnoreDifferences) (*IgnoreNormalizer, error) {
+	return NewIgnoreNormalizerWithOpts(ignore, IgnoreNormalizerOpts{})
+}
+
+// NewIgnoreNormalizerWithOpts is like NewIgnoreNormalizer, with opts limiting the evaluation of jq path
+// expressions. The zero value of opts applies the defaults.
+func NewIgnoreNormalizerWithOpts(ignore v1alpha1.IgnoreDifferences, opts IgnoreNormalizerOpts) (*IgnoreNormalizer, error) {
+	n := &IgnoreNormalizer{}
+	for i, rule := range ignore {
+		r := &ignoreRule{index: i, rule: rule}
+
+		var err error
+		if r.group, err = glob.Compile(rule.Group); err != nil {
+			return nil, fmt.Errorf("ignoreDifferences[%d]: invalid group %q: %w", i, rule.Group, err)
+		}
+		if r.kind, err = glob.Compile(rule.Kind); err != nil {
+			return nil, fmt.Errorf("ignoreDifferences[%d]: invalid kind %q: %w", i, rule.Kind, err)
+		}
+		for _, p := range rule.JSONPointers {
+			pointer, err := parseJSONPointer(p)
+			if err != nil {
+				return nil, fmt.Errorf("ignoreDifferences[%d]: %w", i, err)
+			}
+			r.pointers = append(r.pointers, pointer)
+		}
+		for _, expression := range rule.JQPathExpressions {
+			deletion, err := compileJQPathExpression(expression, opts)
+			if err != nil {
+				return nil, fmt.Errorf("ignoreDifferences[%d]: %w", i, err)
+			}
+			r.jq = append(r.jq, deletion)
+		}
+		n.rules = append(n.rules, r)
+	}
+	return n, nil
+}
+
+// NewApplicationIgnoreNormalizer returns a normalizer for the ignoreDifferences of the given application
+func NewApplicationIgnoreNormalizer(app *v1alpha1.Application) (*IgnoreNormalizer, error) {
+	return NewIgnoreNormalizer(app.Spec.IgnoreDifferences)
+}
+
+// NewApplicationIgnoreNormalizerWithOpts is like NewApplicationIgnoreNormalizer, with opts limiting the
+// evaluation of jq path expressions
+func NewApplicationIgnoreNormalizerWithOpts(app *v1alpha1.Application, opts IgnoreNormalizerOpts) (*IgnoreNormalizer, error) {
+	return NewIgnoreNormalizerWithOpts(app.Spec.IgnoreDifferences, opts)
+}
+
+// Normalize removes all ignored fields from obj in place and returns the rules that matched it. Fields owned
+// by one of the ManagedFieldsManagers of a matching rule are looked up in the managed fields of obj itself.
+func (n *IgnoreNormalizer) Normalize(obj *unstructured.Unstructured) ([]MatchedRule, error) {
+	return n.normalize(obj, obj)
+}
+
+// NormalizeLiveAndDesired removes all ignored fields from both objects in place and returns the rules that
+// matched. As desired manifests carry no managed fields, fields owned by one of the ManagedFieldsManagers
+// of a matching rule are taken from the live object and removed from both. Either object may be nil. The
+// returned rules hold the jq errors of both objects.
+func (n *IgnoreNormalizer) NormalizeLiveAndDesired(live, desired *unstructured.Unstructured) ([]MatchedRule, error) {
+	ref := live
+	if ref == nil {
+		ref = desired
+	}
+	if ref == nil {
+		return nil, nil
+	}
+	matched, err := n.normalize(live, ref)
+	if err != nil {
+		return nil, err
+	}
+	desiredMatched, err := n.normalize(desired, ref)
+	if err != nil {
+		return nil, err
+	}
+	// both objects are matched against ref, so the matched rules line up
+	for i := range matched {
+		matched[i].Errors = append(matched[i].Errors, desiredMatched[i].Errors...)
+	}
+	return matched, nil
+}
+
+// matches returns true if the rule applies to the given object
+func (r *ignoreRule) matches(obj *unstructured.Unstructured) bool {
+	gk := obj.GroupVersionKind().GroupKind()
+	return r.group.Match(gk.Group) &&
+		r.kind.Match(gk.Kind) &&
+		(r.rule.Name == "" || r.rule.Name == obj.GetName()) &&
+		(r.rule.Namespace == "" || r.rule.Namespace == obj.GetNamespace())
+}
+
+// normalize applies the rules matching ref to obj, using the managed fields of ref
+func (n *IgnoreNormalizer) normalize(obj, ref *unstructured.Unstructured) ([]MatchedRule, error) {
+	var matched []MatchedRule
+	for _, r := range n.rules {
+		if !r.matches(ref) {
+			continue
+		}
+		match := MatchedRule{Index: r.index, Rule: r.rule}
+		if obj == nil {
+			matched = append(matched, match)
+			continue
+		}
+
+		for _, pointer := range r.pointers {
+			pointer.remove(obj.Object)
+		}
+		for _, deletion := range r.jq {
+			if err := deletion.apply(obj); err != nil {
+				match.Errors = append(match.Errors, err)
+			}
+		}
+		if len(r.rule.ManagedFieldsManagers) > 0 {
+			if err := removeManagedFields(obj, ref.GetManagedFields(), r.rule.ManagedFieldsManagers); err != nil {
+				return nil, fmt.Errorf("ignoreDifferences[%d]: %w", r.index, err)
+			}
+		}
+		matched = append(matched, match)
+	}
+	return matched, nil
+}
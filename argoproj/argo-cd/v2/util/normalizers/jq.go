@@ -0,0 +1,144 @@
+package normalizers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DefaultJQExecutionTimeout is the default time a single jq path expression may run, as in Argo CD
+	DefaultJQExecutionTimeout = 1 * time.Second
+	// DefaultJQMaxComplexity is the default maximum number of query and term nodes of a jq path expression
+	DefaultJQMaxComplexity = 500
+)
+
+// ErrJQTimeout is returned when the evaluation of a jq path expression exceeds the execution timeout
+var ErrJQTimeout = errors.New("jq path expression execution timed out")
+
+// IgnoreNormalizerOpts controls the evaluation of jq path expressions
+type IgnoreNormalizerOpts struct {
+	// JQExecutionTimeout is the maximum time a single jq path expression may run. Defaults to DefaultJQExecutionTimeout.
+	JQExecutionTimeout time.Duration
+	// JQMaxComplexity is the maximum number of query and term nodes of a parsed jq path expression.
+	// Defaults to DefaultJQMaxComplexity.
+	JQMaxComplexity int
+}
+
+func (o IgnoreNormalizerOpts) executionTimeout() time.Duration {
+	if o.JQExecutionTimeout <= 0 {
+		return DefaultJQExecutionTimeout
+	}
+	return o.JQExecutionTimeout
+}
+
+func (o IgnoreNormalizerOpts) maxComplexity() int {
+	if o.JQMaxComplexity <= 0 {
+		return DefaultJQMaxComplexity
+	}
+	return o.JQMaxComplexity
+}
+
+// jqDeletion removes the paths produced by a jq path expression
+type jqDeletion struct {
+	expression string
+	code       *gojq.Code
+	timeout    time.Duration
+}
+
+// compileJQPathExpression compiles the deletion query for a jq path expression, the same way Argo CD does
+func compileJQPathExpression(expression string, opts IgnoreNormalizerOpts) (*jqDeletion, error) {
+	query, err := gojq.Parse(fmt.Sprintf("del(%s)", expression))
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq path expression %q: %w", expression, err)
+	}
+	if complexity := jqComplexity(reflect.ValueOf(query)); complexity > opts.maxComplexity() {
+		return nil, fmt.Errorf("jq path expression %q is too complex (%d nodes, limit %d)", expression, complexity, opts.maxComplexity())
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq path expression %q: %w", expression, err)
+	}
+	return &jqDeletion{expression: expression, code: code, timeout: opts.executionTimeout()}, nil
+}
+
+// apply removes the paths matched by the expression from obj. obj is only modified if the evaluation succeeds.
+func (d *jqDeletion) apply(obj *unstructured.Unstructured) error {
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+	// gojq only supports the types produced by encoding/json
+	var input map[string]any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	iter := d.code.RunWithContext(ctx, input)
+	first, ok := iter.Next()
+	if !ok {
+		return fmt.Errorf("jq path expression %q did not return any data", d.expression)
+	}
+	if err, ok := first.(error); ok {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %v: %q", ErrJQTimeout, d.timeout, d.expression)
+		}
+		return fmt.Errorf("jq path expression %q returned error: %w", d.expression, err)
+	}
+	if _, ok := iter.Next(); ok {
+		return fmt.Errorf("jq path expression %q returned multiple objects", d.expression)
+	}
+
+	data, err = json.Marshal(first)
+	if err != nil {
+		return err
+	}
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("jq path expression %q returned invalid object: %w", d.expression, err)
+	}
+	obj.Object = result.Object
+	return nil
+}
+
+var (
+	jqQueryType = reflect.TypeOf(gojq.Query{})
+	jqTermType  = reflect.TypeOf(gojq.Term{})
+)
+
+// jqComplexity counts the query and term nodes of a parsed jq expression
+func jqComplexity(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return jqComplexity(v.Elem())
+	case reflect.Slice:
+		n := 0
+		for i := 0; i < v.Len(); i++ {
+			n += jqComplexity(v.Index(i))
+		}
+		return n
+	case reflect.Struct:
+		n := 0
+		if v.Type() == jqQueryType || v.Type() == jqTermType {
+			n++
+		}
+		for i := 0; i < v.NumField(); i++ {
+			n += jqComplexity(v.Field(i))
+		}
+		return n
+	default:
+		return 0
+	}
+}
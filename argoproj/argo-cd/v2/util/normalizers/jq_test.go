@@ -0,0 +1,210 @@
+package normalizers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// deploymentFixture is the deployment the upstream normalizer tests run jq path expressions against
+const deploymentFixture = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  namespace: default
+  labels:
+    app: nginx
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      initContainers:
+      - name: init-container-0
+        image: busybox
+      - name: init-container-1
+        image: busybox
+      containers:
+      - name: nginx
+        image: nginx:1.27
+        env:
+        - name: A
+          value: a
+        - name: B
+          value: b
+`
+
+func fixture(t *testing.T, mutate func(obj map[string]any)) *unstructured.Unstructured {
+	t.Helper()
+	data, err := yaml.YAMLToJSON([]byte(deploymentFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if mutate != nil {
+		mutate(obj.Object)
+	}
+	return obj
+}
+
+func podSpec(obj map[string]any) map[string]any {
+	spec, _, _ := unstructured.NestedMap(obj, "spec", "template", "spec")
+	return spec
+}
+
+func setPodSpec(obj map[string]any, spec map[string]any) {
+	_ = unstructured.SetNestedMap(obj, spec, "spec", "template", "spec")
+}
+
+func TestJQPathExpressions(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		expressions []string
+		expected    func(obj map[string]any)
+		errors      int
+	}{
+		{
+			name:        "select list item",
+			expressions: []string{`.spec.template.spec.initContainers[] | select(.name == "init-container-0")`},
+			expected: func(obj map[string]any) {
+				spec := podSpec(obj)
+				spec["initContainers"] = spec["initContainers"].([]any)[1:]
+				setPodSpec(obj, spec)
+			},
+		},
+		{
+			name:        "select field of list item",
+			expressions: []string{`.spec.template.spec.containers[] | select(.name == "nginx") | .image`},
+			expected: func(obj map[string]any) {
+				spec := podSpec(obj)
+				delete(spec["containers"].([]any)[0].(map[string]any), "image")
+				setPodSpec(obj, spec)
+			},
+		},
+		{
+			name:        "nested select",
+			expressions: []string{`.spec.template.spec.containers[].env[] | select(.name == "B")`},
+			expected: func(obj map[string]any) {
+				spec := podSpec(obj)
+				container := spec["containers"].([]any)[0].(map[string]any)
+				container["env"] = container["env"].([]any)[:1]
+				setPodSpec(obj, spec)
+			},
+		},
+		{
+			name:        "plain path",
+			expressions: []string{`.metadata.labels`},
+			expected: func(obj map[string]any) {
+				unstructured.RemoveNestedField(obj, "metadata", "labels")
+			},
+		},
+		{
+			name:        "multiple expressions",
+			expressions: []string{`.spec.replicas`, `.spec.template.spec.initContainers`},
+			expected: func(obj map[string]any) {
+				unstructured.RemoveNestedField(obj, "spec", "replicas")
+				unstructured.RemoveNestedField(obj, "spec", "template", "spec", "initContainers")
+			},
+		},
+		{
+			name:        "no match leaves object unchanged",
+			expressions: []string{`.spec.template.spec.containers[] | select(.name == "missing")`},
+		},
+		{
+			name:        "missing field leaves object unchanged",
+			expressions: []string{`.spec.missing`},
+		},
+		{
+			name:        "evaluation error leaves object unchanged",
+			expressions: []string{`.spec.missing.foo[]`},
+			errors:      1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			normalizer, err := NewIgnoreNormalizer(v1alpha1.IgnoreDifferences{{
+				Group:             "apps",
+				Kind:              "Deployment",
+				JQPathExpressions: tc.expressions,
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj := fixture(t, nil)
+			matched, err := normalizer.Normalize(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(matched) != 1 {
+				t.Fatalf("expected the rule to match, got %v", matched)
+			}
+			if len(matched[0].Errors) != tc.errors {
+				t.Errorf("expected %d errors, got %v", tc.errors, matched[0].Errors)
+			}
+			if expected := fixture(t, tc.expected); !reflect.DeepEqual(obj.Object, expected.Object) {
+				t.Errorf("unexpected result\n got: %v\nwant: %v", obj.Object, expected.Object)
+			}
+		})
+	}
+}
+
+func TestJQPathExpressionTimeout(t *testing.T) {
+	normalizer, err := NewIgnoreNormalizerWithOpts(v1alpha1.IgnoreDifferences{{
+		Group:             "apps",
+		Kind:              "Deployment",
+		JQPathExpressions: []string{`until(false; .)`},
+	}}, IgnoreNormalizerOpts{JQExecutionTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := fixture(t, nil)
+	matched, err := normalizer.Normalize(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 1 || len(matched[0].Errors) != 1 || !errors.Is(matched[0].Errors[0], ErrJQTimeout) {
+		t.Fatalf("expected a timeout error, got %v", matched)
+	}
+	if expected := fixture(t, nil); !reflect.DeepEqual(obj.Object, expected.Object) {
+		t.Error("expected the object to be unchanged after a timeout")
+	}
+}
+
+func TestJQPathExpressionComplexity(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		expression string
+		opts       IgnoreNormalizerOpts
+		err        string
+	}{
+		{name: "simple", expression: `.spec.replicas`},
+		{name: "default limit", expression: strings.Repeat(".a | ", DefaultJQMaxComplexity) + ".a", err: "too complex"},
+		{name: "custom limit", expression: `.spec.template.spec.containers[] | select(.name == "nginx")`, opts: IgnoreNormalizerOpts{JQMaxComplexity: 3}, err: "too complex"},
+		{name: "within custom limit", expression: `.spec.template.spec.containers[] | select(.name == "nginx")`, opts: IgnoreNormalizerOpts{JQMaxComplexity: 100}},
+		{name: "syntax error", expression: `.spec[`, err: "invalid jq path expression"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewIgnoreNormalizerWithOpts(v1alpha1.IgnoreDifferences{{
+				Kind:              "Deployment",
+				JQPathExpressions: []string{tc.expression},
+			}}, tc.opts)
+			switch {
+			case tc.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
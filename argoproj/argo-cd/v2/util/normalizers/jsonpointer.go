@@ -0,0 +1,57 @@
+package normalizers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPointer is a parsed RFC 6901 JSON pointer
+type jsonPointer []string
+
+// parseJSONPointer parses a JSON pointer such as /spec/template/metadata/annotations/foo~1bar
+func parseJSONPointer(pointer string) (jsonPointer, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// remove deletes the value the pointer points to from the given document. Pointers to values that do not
+// exist are ignored, the same way Argo CD ignores failing remove patches.
+func (p jsonPointer) remove(doc map[string]any) {
+	removeToken(doc, p)
+}
+
+// removeToken removes the value at tokens from node and returns the resulting node
+func removeToken(node any, tokens []string) any {
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[tokens[0]]
+		if !ok {
+			return n
+		}
+		if len(tokens) == 1 {
+			delete(n, tokens[0])
+		} else {
+			n[tokens[0]] = removeToken(child, tokens[1:])
+		}
+		return n
+	case []any:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i >= len(n) {
+			return n
+		}
+		if len(tokens) == 1 {
+			return append(n[:i:i], n[i+1:]...)
+		}
+		n[i] = removeToken(n[i], tokens[1:])
+		return n
+	default:
+		return node
+	}
+}
@@ -0,0 +1,141 @@
+package normalizers
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+)
+
+// removeManagedFields removes all leaf fields from obj that are owned by one of the given managers according
+// to managedFields. Maps and list items that become empty through the removal are removed as well.
+func removeManagedFields(obj *unstructured.Unstructured, managedFields []metav1.ManagedFieldsEntry, managers []string) error {
+	for _, entry := range managedFields {
+		if !slices.Contains(managers, entry.Manager) || entry.FieldsV1 == nil {
+			continue
+		}
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return fmt.Errorf("failed to parse managed fields of manager %q: %w", entry.Manager, err)
+		}
+		set.Leaves().Iterate(func(path fieldpath.Path) {
+			removeFieldPath(obj.Object, path)
+		})
+	}
+	return nil
+}
+
+// removeFieldPath removes the value at path from node and returns the resulting node
+func removeFieldPath(node any, path fieldpath.Path) any {
+	pe := path[0]
+	switch n := node.(type) {
+	case map[string]any:
+		if pe.FieldName == nil {
+			return n
+		}
+		child, ok := n[*pe.FieldName]
+		if !ok {
+			return n
+		}
+		if len(path) == 1 {
+			delete(n, *pe.FieldName)
+			return n
+		}
+		child = removeFieldPath(child, path[1:])
+		if isEmpty(child) {
+			delete(n, *pe.FieldName)
+		} else {
+			n[*pe.FieldName] = child
+		}
+		return n
+	case []any:
+		i := listIndex(n, pe)
+		if i < 0 {
+			return n
+		}
+		if len(path) > 1 {
+			n[i] = removeFieldPath(n[i], path[1:])
+			if !isEmpty(n[i]) {
+				return n
+			}
+		}
+		return append(n[:i:i], n[i+1:]...)
+	default:
+		return node
+	}
+}
+
+// listIndex returns the index of the list item the path element selects or -1 if there is none
+func listIndex(list []any, pe fieldpath.PathElement) int {
+	switch {
+	case pe.Index != nil:
+		if *pe.Index >= 0 && *pe.Index < len(list) {
+			return *pe.Index
+		}
+	case pe.Value != nil:
+		want := (*pe.Value).Unstructured()
+		for i, item := range list {
+			if valuesEqual(item, want) {
+				return i
+			}
+		}
+	case pe.Key != nil:
+		for i, item := range list {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			matches := true
+			for _, field := range *pe.Key {
+				if !valuesEqual(m[field.Name], field.Value.Unstructured()) {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// valuesEqual compares two unstructured values, treating integers and floats of the same value as equal
+func valuesEqual(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// isEmpty returns true for empty maps and lists
+func isEmpty(v any) bool {
+	switch n := v.(type) {
+	case map[string]any:
+		return len(n) == 0
+	case []any:
+		return len(n) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,232 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Notification is a rendered template sent because a trigger fired
+type Notification struct {
+	// Trigger is the name of the trigger that fired
+	Trigger string
+	// Template is the name of the rendered template
+	Template string
+	Title    string
+	Message  string
+	// Subscriptions holds the subscriptions to the trigger, if evaluated with NotifySubscriptions
+	Subscriptions []Subscription
+}
+
+// Engine evaluates triggers against application snapshots and renders the templates of the triggers that
+// fired. It remembers which conditions fired per application, so a condition only fires again once it no
+// longer held or, for conditions with OncePer, once the OncePer value changed. It is not safe for concurrent
+// use.
+type Engine struct {
+	// Context is passed to templates as .context, for example argocdUrl
+	Context map[string]string
+	// DefaultTriggers are the triggers of subscriptions without trigger, which EffectiveSubscriptions keeps
+	// under the empty trigger if called without default triggers
+	DefaultTriggers []string
+
+	triggers  map[string]Trigger
+	templates map[string]*compiledTemplate
+	// notified holds the conditions that fired per application
+	notified map[string]map[string]bool
+}
+
+type compiledTemplate struct {
+	title   *template.Template
+	message *template.Template
+}
+
+// NewEngine returns an engine for the given triggers and templates. Templates referenced by a trigger must
+// exist.
+func NewEngine(triggers []Trigger, templates []Template) (*Engine, error) {
+	e := &Engine{
+		Context:   map[string]string{},
+		triggers:  map[string]Trigger{},
+		templates: map[string]*compiledTemplate{},
+		notified:  map[string]map[string]bool{},
+	}
+	for _, t := range templates {
+		title, err := template.New(t.Name).Parse(t.Title)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: invalid title: %w", t.Name, err)
+		}
+		message, err := template.New(t.Name).Parse(t.Message)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: invalid message: %w", t.Name, err)
+		}
+		e.templates[t.Name] = &compiledTemplate{title: title, message: message}
+	}
+	for _, trigger := range triggers {
+		for i, condition := range trigger.Conditions {
+			if condition.When == nil {
+				return nil, fmt.Errorf("trigger %s: condition %d has no when", trigger.Name, i)
+			}
+			for _, name := range condition.Send {
+				if _, ok := e.templates[name]; !ok {
+					return nil, fmt.Errorf("trigger %s: template %s not found", trigger.Name, name)
+				}
+			}
+		}
+		e.triggers[trigger.Name] = trigger
+	}
+	return e, nil
+}
+
+// NewBuiltinEngine returns an engine with the built-in triggers and templates
+func NewBuiltinEngine() *Engine {
+	e, err := NewEngine(BuiltinTriggers(), BuiltinTemplates())
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Evaluate evaluates the named triggers against app and returns the notifications of the conditions that
+// fired, in the order of the triggers and their conditions
+func (e *Engine) Evaluate(app *v1alpha1.Application, triggers ...string) ([]Notification, error) {
+	var notifications []Notification
+	var appContext map[string]any
+	for _, name := range triggers {
+		trigger, ok := e.triggers[name]
+		if !ok {
+			return nil, fmt.Errorf("trigger %s not found", name)
+		}
+		for i, condition := range trigger.Conditions {
+			if !e.fire(app, name, i, condition) {
+				continue
+			}
+			if appContext == nil {
+				obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(app)
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert application: %w", err)
+				}
+				appContext = obj
+			}
+			for _, templateName := range condition.Send {
+				n, err := e.render(templateName, appContext)
+				if err != nil {
+					return nil, err
+				}
+				n.Trigger = name
+				notifications = append(notifications, n)
+			}
+		}
+	}
+	return notifications, nil
+}
+
+// NotifySubscriptions evaluates the triggers of the given subscriptions, as returned by
+// EffectiveSubscriptions, and attaches the subscriptions to the notifications. Subscriptions under the empty
+// trigger apply to each of DefaultTriggers. Subscriptions to triggers the engine does not know are ignored, so
+// a single subscription to a trigger that is not configured does not prevent the others from being notified.
+func (e *Engine) NotifySubscriptions(app *v1alpha1.Application, subscriptions map[string][]Subscription) ([]Notification, error) {
+	byTrigger := map[string][]Subscription{}
+	for trigger, s := range subscriptions {
+		if trigger != "" {
+			byTrigger[trigger] = append(byTrigger[trigger], s...)
+		}
+	}
+	if defaults := subscriptions[""]; len(defaults) > 0 {
+		for _, trigger := range e.DefaultTriggers {
+			for _, s := range defaults {
+				byTrigger[trigger] = append(byTrigger[trigger], Subscription{Trigger: trigger, Service: s.Service, Recipients: s.Recipients})
+			}
+		}
+	}
+
+	var triggers []string
+	for trigger, s := range byTrigger {
+		if _, ok := e.triggers[trigger]; !ok {
+			continue
+		}
+		byTrigger[trigger] = mergeSubscriptions(s)
+		triggers = append(triggers, trigger)
+	}
+	sort.Strings(triggers)
+	notifications, err := e.Evaluate(app, triggers...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range notifications {
+		notifications[i].Subscriptions = byTrigger[notifications[i].Trigger]
+	}
+	return notifications, nil
+}
+
+// Reset forgets which conditions fired for app, for example once it was deleted
+func (e *Engine) Reset(app *v1alpha1.Application) {
+	delete(e.notified, appKey(app))
+}
+
+// Retain forgets which conditions fired for all applications but apps, for example to forget deleted
+// applications after listing the existing ones
+func (e *Engine) Retain(apps ...*v1alpha1.Application) {
+	keep := map[string]bool{}
+	for _, app := range apps {
+		keep[appKey(app)] = true
+	}
+	for key := range e.notified {
+		if !keep[key] {
+			delete(e.notified, key)
+		}
+	}
+}
+
+// fire records the result of the condition and returns true if it should send its templates. Only the
+// current OncePer value of a condition is remembered, older values are dropped once it changes.
+func (e *Engine) fire(app *v1alpha1.Application, trigger string, index int, condition Condition) bool {
+	prefix := fmt.Sprintf("%s[%d]", trigger, index)
+	key := prefix
+	if condition.OncePer != nil {
+		key += ":" + condition.OncePer(app)
+	}
+	notified := e.notified[appKey(app)]
+	for k := range notified {
+		if k != key && strings.HasPrefix(k, prefix+":") {
+			delete(notified, k)
+		}
+	}
+	if !condition.When(app) {
+		delete(notified, key)
+		if len(notified) == 0 {
+			delete(e.notified, appKey(app))
+		}
+		return false
+	}
+	if notified[key] {
+		return false
+	}
+	if notified == nil {
+		notified = map[string]bool{}
+		e.notified[appKey(app)] = notified
+	}
+	notified[key] = true
+	return true
+}
+
+func (e *Engine) render(name string, app map[string]any) (Notification, error) {
+	t := e.templates[name]
+	data := map[string]any{"app": app, "context": e.Context}
+	title := &bytes.Buffer{}
+	if err := t.title.Execute(title, data); err != nil {
+		return Notification{}, fmt.Errorf("template %s: failed to render title: %w", name, err)
+	}
+	message := &bytes.Buffer{}
+	if err := t.message.Execute(message, data); err != nil {
+		return Notification{}, fmt.Errorf("template %s: failed to render message: %w", name, err)
+	}
+	return Notification{Template: name, Title: title.String(), Message: message.String()}, nil
+}
+
+func appKey(app *v1alpha1.Application) string {
+	return app.Namespace + "/" + app.Name
+}
@@ -0,0 +1,83 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func degradedApp() *v1alpha1.Application {
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{
+		Name:      "app",
+		Namespace: "argocd",
+		Annotations: map[string]string{
+			SubscribeAnnotationPrefix + "slack":                            "default",
+			SubscribeAnnotationPrefix + "on-custom.slack":                  "custom",
+			SubscribeAnnotationPrefix + TriggerOnHealthDegraded + ".email": "ops@example.com",
+		},
+	}}
+	app.Status.Health.Status = v1alpha1.HealthStatusDegraded
+	return app
+}
+
+func TestNotifySubscriptions(t *testing.T) {
+	app := degradedApp()
+	subscriptions, err := EffectiveSubscriptions(nil, app, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewBuiltinEngine()
+	e.DefaultTriggers = []string{TriggerOnHealthDegraded}
+	notifications, err := e.NotifySubscriptions(app, subscriptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 || notifications[0].Trigger != TriggerOnHealthDegraded {
+		t.Fatalf("unexpected notifications %+v", notifications)
+	}
+	services := map[string]bool{}
+	for _, s := range notifications[0].Subscriptions {
+		services[s.Service] = true
+	}
+	if !services["slack"] || !services["email"] || len(services) != 2 {
+		t.Errorf("unexpected subscriptions %+v", notifications[0].Subscriptions)
+	}
+
+	// without default triggers, subscriptions without trigger are ignored
+	e = NewBuiltinEngine()
+	notifications, err = e.NotifySubscriptions(app, subscriptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 || len(notifications[0].Subscriptions) != 1 {
+		t.Errorf("unexpected notifications %+v", notifications)
+	}
+}
+
+func TestEngineOncePerPruning(t *testing.T) {
+	revision := "a"
+	e, err := NewEngine([]Trigger{{Name: "on-revision", Conditions: []Condition{{
+		When:    func(*v1alpha1.Application) bool { return true },
+		OncePer: func(*v1alpha1.Application) string { return revision },
+	}}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := degradedApp()
+	for _, r := range []string{"a", "a", "b", "c", "c"} {
+		revision = r
+		if _, err := e.Evaluate(app, "on-revision"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if notified := e.notified[appKey(app)]; len(notified) != 1 || !notified["on-revision[0]:c"] {
+		t.Errorf("unexpected state %v", notified)
+	}
+
+	e.Retain()
+	if len(e.notified) != 0 {
+		t.Errorf("expected state of deleted applications to be dropped, got %v", e.notified)
+	}
+}
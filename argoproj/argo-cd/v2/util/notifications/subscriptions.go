@@ -0,0 +1,171 @@
+// Package notifications parses the subscriptions of Applications and AppProjects to Argo CD notifications
+// and evaluates notification triggers.
+package notifications
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationPrefix is the prefix of all notification annotations
+	AnnotationPrefix = "notifications.argoproj.io"
+	// SubscribeAnnotationPrefix is the prefix of subscription annotations, which have the form
+	// subscribe.<trigger>.<service> or subscribe.<service> for the default triggers
+	SubscribeAnnotationPrefix = AnnotationPrefix + "/subscribe."
+
+	recipientSeparator = ";"
+)
+
+// Subscription is the subscription of recipients of a service to a trigger
+type Subscription struct {
+	// Trigger is the name of the trigger, empty for the default triggers
+	Trigger string
+	// Service is the notification service, such as slack
+	Service string
+	// Recipients are the recipients of the service, such as channel names
+	Recipients []string
+}
+
+// AnnotationKey returns the key of the annotation holding the subscription
+func (s Subscription) AnnotationKey() string {
+	if s.Trigger == "" {
+		return SubscribeAnnotationPrefix + s.Service
+	}
+	return SubscribeAnnotationPrefix + s.Trigger + "." + s.Service
+}
+
+// AnnotationValue returns the value of the annotation holding the subscription
+func (s Subscription) AnnotationValue() string {
+	return strings.Join(s.Recipients, recipientSeparator)
+}
+
+// ParseSubscriptions returns the subscriptions of the given annotations, ordered by trigger and service.
+// Annotations that are not subscriptions are ignored.
+func ParseSubscriptions(annotations map[string]string) ([]Subscription, error) {
+	var subscriptions []Subscription
+	for key, value := range annotations {
+		rest, ok := strings.CutPrefix(key, SubscribeAnnotationPrefix)
+		if !ok {
+			continue
+		}
+		s := Subscription{Recipients: ParseRecipients(value)}
+		switch parts := strings.Split(rest, "."); len(parts) {
+		case 1:
+			s.Service = parts[0]
+		case 2:
+			s.Trigger, s.Service = parts[0], parts[1]
+		default:
+			return nil, fmt.Errorf("invalid subscription annotation %s: expected subscribe.<trigger>.<service>", key)
+		}
+		if s.Service == "" {
+			return nil, fmt.Errorf("invalid subscription annotation %s: service is missing", key)
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	sortSubscriptions(subscriptions)
+	return subscriptions, nil
+}
+
+// ParseRecipients splits a ; separated list of recipients, dropping empty entries
+func ParseRecipients(value string) []string {
+	var recipients []string
+	for _, r := range strings.Split(value, recipientSeparator) {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients
+}
+
+// SetSubscriptions replaces all subscription annotations of obj with the given subscriptions. Subscriptions
+// without recipients are dropped.
+func SetSubscriptions(obj metav1.Object, subscriptions []Subscription) {
+	annotations := map[string]string{}
+	for key, value := range obj.GetAnnotations() {
+		if !strings.HasPrefix(key, SubscribeAnnotationPrefix) {
+			annotations[key] = value
+		}
+	}
+	for _, s := range mergeSubscriptions(subscriptions) {
+		if len(s.Recipients) > 0 {
+			annotations[s.AnnotationKey()] = s.AnnotationValue()
+		}
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// EffectiveSubscriptions merges the subscriptions of the project and the application into one list per
+// trigger. Subscriptions to the default triggers are added to each of defaultTriggers, or kept under the empty
+// trigger if there are none. Recipients of the same trigger and service are merged without duplicates. project
+// may be nil.
+func EffectiveSubscriptions(project *v1alpha1.AppProject, app *v1alpha1.Application, defaultTriggers []string) (map[string][]Subscription, error) {
+	var all []Subscription
+	if project != nil {
+		subscriptions, err := ParseSubscriptions(project.GetAnnotations())
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project.Name, err)
+		}
+		all = append(all, subscriptions...)
+	}
+	subscriptions, err := ParseSubscriptions(app.GetAnnotations())
+	if err != nil {
+		return nil, fmt.Errorf("application %s: %w", app.Name, err)
+	}
+	all = append(all, subscriptions...)
+
+	var expanded []Subscription
+	for _, s := range all {
+		if s.Trigger != "" || len(defaultTriggers) == 0 {
+			expanded = append(expanded, s)
+			continue
+		}
+		for _, trigger := range defaultTriggers {
+			expanded = append(expanded, Subscription{Trigger: trigger, Service: s.Service, Recipients: s.Recipients})
+		}
+	}
+
+	byTrigger := map[string][]Subscription{}
+	for _, s := range mergeSubscriptions(expanded) {
+		byTrigger[s.Trigger] = append(byTrigger[s.Trigger], s)
+	}
+	return byTrigger, nil
+}
+
+// mergeSubscriptions merges the recipients of subscriptions with the same trigger and service, keeping the
+// order of the recipients, and returns them ordered by trigger and service
+func mergeSubscriptions(subscriptions []Subscription) []Subscription {
+	type key struct{ trigger, service string }
+	index := map[key]int{}
+	var merged []Subscription
+	for _, s := range subscriptions {
+		k := key{s.Trigger, s.Service}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(merged)
+			merged = append(merged, Subscription{Trigger: s.Trigger, Service: s.Service})
+			i = len(merged) - 1
+		}
+		for _, r := range s.Recipients {
+			if !slices.Contains(merged[i].Recipients, r) {
+				merged[i].Recipients = append(merged[i].Recipients, r)
+			}
+		}
+	}
+	sortSubscriptions(merged)
+	return merged
+}
+
+func sortSubscriptions(subscriptions []Subscription) {
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		if subscriptions[i].Trigger != subscriptions[j].Trigger {
+			return subscriptions[i].Trigger < subscriptions[j].Trigger
+		}
+		return subscriptions[i].Service < subscriptions[j].Service
+	})
+}
@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const (
+	// TriggerOnSyncSucceeded fires when a sync operation succeeded
+	TriggerOnSyncSucceeded = "on-sync-succeeded"
+	// TriggerOnSyncFailed fires when a sync operation failed or errored
+	TriggerOnSyncFailed = "on-sync-failed"
+	// TriggerOnHealthDegraded fires when the application health is Degraded
+	TriggerOnHealthDegraded = "on-health-degraded"
+	// TriggerOnDeployed fires once per synced revision when a sync succeeded and the application is Healthy
+	TriggerOnDeployed = "on-deployed"
+)
+
+// Condition is a condition of a trigger. When it holds, the templates in Send are sent.
+type Condition struct {
+	// Description describes the condition
+	Description string
+	// When returns true if the trigger fires for app
+	When func(app *v1alpha1.Application) bool
+	// OncePer returns the value the condition fires once for, such as the synced revision. If nil, the
+	// condition fires each time When becomes true.
+	OncePer func(app *v1alpha1.Application) string
+	// Send holds the names of the templates to send
+	Send []string
+}
+
+// Trigger is a named list of conditions
+type Trigger struct {
+	// Name is the name subscriptions refer to the trigger by
+	Name string
+	// Conditions are evaluated independently, each one may send its templates
+	Conditions []Condition
+}
+
+// Template is a notification template. Title and Message are Go text templates, rendered with the
+// application as .app and the engine context as .context, the same way Argo CD notifications does.
+type Template struct {
+	// Name is the name conditions refer to the template by
+	Name string
+	// Title is the subject of the notification
+	Title string
+	// Message is the body of the notification
+	Message string
+}
+
+// BuiltinTriggers returns the triggers of the Argo CD notifications catalog that do not need an expression
+// engine
+func BuiltinTriggers() []Trigger {
+	return []Trigger{
+		{Name: TriggerOnSyncSucceeded, Conditions: []Condition{{
+			Description: "Application syncing has succeeded",
+			When:        operationPhaseIn(v1alpha1.OperationSucceeded),
+			Send:        []string{"app-sync-succeeded"},
+		}}},
+		{Name: TriggerOnSyncFailed, Conditions: []Condition{{
+			Description: "Application syncing has failed",
+			When:        operationPhaseIn(v1alpha1.OperationError, v1alpha1.OperationFailed),
+			Send:        []string{"app-sync-failed"},
+		}}},
+		{Name: TriggerOnHealthDegraded, Conditions: []Condition{{
+			Description: "Application has degraded",
+			When: func(app *v1alpha1.Application) bool {
+				return app.Status.Health.Status == v1alpha1.HealthStatusDegraded
+			},
+			Send: []string{"app-health-degraded"},
+		}}},
+		{Name: TriggerOnDeployed, Conditions: []Condition{{
+			Description: "Application is synced and healthy. Triggered once per commit.",
+			When: func(app *v1alpha1.Application) bool {
+				return operationPhaseIn(v1alpha1.OperationSucceeded)(app) && app.Status.Health.Status == v1alpha1.HealthStatusHealthy
+			},
+			OncePer: syncedRevision,
+			Send:    []string{"app-deployed"},
+		}}},
+	}
+}
+
+// BuiltinTemplates returns the templates the built-in triggers send
+func BuiltinTemplates() []Template {
+	return []Template{
+		{
+			Name:    "app-sync-succeeded",
+			Title:   "Application {{.app.metadata.name}} has been successfully synced.",
+			Message: "Application {{.app.metadata.name}} has been successfully synced at {{.app.status.operationState.finishedAt}}.\nSync operation details are available at: {{.context.argocdUrl}}/applications/{{.app.metadata.name}}?operation=true .",
+		},
+		{
+			Name:    "app-sync-failed",
+			Title:   "Failed to sync application {{.app.metadata.name}}.",
+			Message: "The sync operation of application {{.app.metadata.name}} has failed at {{.app.status.operationState.finishedAt}} with the following error: {{.app.status.operationState.message}}\nSync operation details are available at: {{.context.argocdUrl}}/applications/{{.app.metadata.name}}?operation=true .",
+		},
+		{
+			Name:    "app-health-degraded",
+			Title:   "Application {{.app.metadata.name}} has degraded.",
+			Message: "Application {{.app.metadata.name}} has degraded.\nApplication details: {{.context.argocdUrl}}/applications/{{.app.metadata.name}}.",
+		},
+		{
+			Name:    "app-deployed",
+			Title:   "New version of an application {{.app.metadata.name}} is up and running.",
+			Message: "Application {{.app.metadata.name}} is now running new version of deployments manifests.",
+		},
+	}
+}
+
+func operationPhaseIn(phases ...v1alpha1.OperationPhase) func(app *v1alpha1.Application) bool {
+	return func(app *v1alpha1.Application) bool {
+		state := app.Status.OperationState
+		if state == nil {
+			return false
+		}
+		for _, phase := range phases {
+			if state.Phase == phase {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// syncedRevision returns the revision(s) of the last sync result
+func syncedRevision(app *v1alpha1.Application) string {
+	state := app.Status.OperationState
+	if state == nil || state.SyncResult == nil {
+		return ""
+	}
+	if len(state.SyncResult.Revisions) > 0 {
+		return strings.Join(state.SyncResult.Revisions, ",")
+	}
+	return state.SyncResult.Revision
+}
@@ -0,0 +1,184 @@
+// Package operation builds the sync and rollback operations of an Application and validates them the same
+// way the Argo CD API server does before accepting them.
+package operation
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// SyncBuilder builds a sync operation. Its methods return the builder so calls can be chained.
+type SyncBuilder struct {
+	op v1alpha1.Operation
+	// exclude holds the resources to skip. Exclude is not serialized, so Build resolves them into the
+	// resources to sync.
+	exclude []v1alpha1.SyncOperationResource
+}
+
+// NewSync returns a builder for a full sync to the revisions of the application spec
+func NewSync() *SyncBuilder {
+	return &SyncBuilder{op: v1alpha1.Operation{Sync: &v1alpha1.SyncOperation{}}}
+}
+
+// NewRollback returns a builder for a rollback to the revision history entry with the given ID. The sync
+// restores the source(s) and revision(s) of the entry and uses the apply strategy, as Argo CD does.
+func NewRollback(app *v1alpha1.Application, id int64) (*SyncBuilder, error) {
+	history := app.Status.History.FindByID(id)
+	if history == nil {
+		return nil, fmt.Errorf("application %s does not have deployment with id %d", app.Name, id)
+	}
+	if history.Source.IsZero() && history.Sources.IsZero() {
+		return nil, fmt.Errorf("cannot rollback application %s to deployment %d: it has no source recorded", app.Name, id)
+	}
+
+	sync := &v1alpha1.SyncOperation{
+		Revision:     history.Revision,
+		Revisions:    append([]string(nil), history.Revisions...),
+		SyncStrategy: &v1alpha1.SyncStrategy{Apply: &v1alpha1.SyncStrategyApply{}},
+	}
+	if !history.Source.IsZero() {
+		sync.Source = history.Source.DeepCopy()
+	}
+	if !history.Sources.IsZero() {
+		sync.Sources = history.Sources.DeepCopy()
+	}
+	return &SyncBuilder{op: v1alpha1.Operation{Sync: sync}}, nil
+}
+
+// WithRevision sets the revision of a single source application
+func (b *SyncBuilder) WithRevision(revision string) *SyncBuilder {
+	b.op.Sync.Revision = revision
+	return b
+}
+
+// WithRevisions sets the revisions of a multi-source application, one per source
+func (b *SyncBuilder) WithRevisions(revisions ...string) *SyncBuilder {
+	b.op.Sync.Revisions = revisions
+	return b
+}
+
+// WithPrune enables or disables the deletion of resources that are no longer part of the sources
+func (b *SyncBuilder) WithPrune(prune bool) *SyncBuilder {
+	b.op.Sync.Prune = prune
+	return b
+}
+
+// WithDryRun enables or disables a dry run
+func (b *SyncBuilder) WithDryRun(dryRun bool) *SyncBuilder {
+	b.op.Sync.DryRun = dryRun
+	return b
+}
+
+// WithApplyStrategy syncs with `kubectl apply` and ignores hooks
+func (b *SyncBuilder) WithApplyStrategy(force bool) *SyncBuilder {
+	b.op.Sync.SyncStrategy = &v1alpha1.SyncStrategy{Apply: &v1alpha1.SyncStrategyApply{Force: force}}
+	return b
+}
+
+// WithHookStrategy syncs with hooks, which is the default if no strategy is set
+func (b *SyncBuilder) WithHookStrategy(force bool) *SyncBuilder {
+	b.op.Sync.SyncStrategy = &v1alpha1.SyncStrategy{Hook: &v1alpha1.SyncStrategyHook{SyncStrategyApply: v1alpha1.SyncStrategyApply{Force: force}}}
+	return b
+}
+
+// WithResources adds resources to a selective sync. Resources with Exclude set are added as with
+// ExcludeResources.
+func (b *SyncBuilder) WithResources(resources ...v1alpha1.SyncOperationResource) *SyncBuilder {
+	for _, res := range resources {
+		if res.Exclude {
+			b.ExcludeResources(res)
+			continue
+		}
+		b.op.Sync.Resources = append(b.op.Sync.Resources, res)
+	}
+	return b
+}
+
+// ExcludeResources adds resources that a selective sync must skip. If a sync only has excluded resources,
+// all other non-hook resources of the application status are synced.
+func (b *SyncBuilder) ExcludeResources(resources ...v1alpha1.SyncOperationResource) *SyncBuilder {
+	for _, res := range resources {
+		res.Exclude = false
+		b.exclude = append(b.exclude, res)
+	}
+	return b
+}
+
+// WithSyncOptions adds per-operation sync options such as Validate=false
+func (b *SyncBuilder) WithSyncOptions(options ...string) *SyncBuilder {
+	for _, option := range options {
+		b.op.Sync.SyncOptions = b.op.Sync.SyncOptions.AddOption(option)
+	}
+	return b
+}
+
+// WithRetry sets the retry strategy of the operation
+func (b *SyncBuilder) WithRetry(retry v1alpha1.RetryStrategy) *SyncBuilder {
+	b.op.Retry = retry
+	return b
+}
+
+// WithInfo adds an informational item to the operation
+func (b *SyncBuilder) WithInfo(name, value string) *SyncBuilder {
+	b.op.Info = append(b.op.Info, &v1alpha1.Info{Name: name, Value: value})
+	return b
+}
+
+// InitiatedBy records the user who requested the operation
+func (b *SyncBuilder) InitiatedBy(username string) *SyncBuilder {
+	b.op.InitiatedBy = v1alpha1.OperationInitiator{Username: username}
+	return b
+}
+
+// InitiatedByAutomation records that the operation was requested by the application controller
+func (b *SyncBuilder) InitiatedByAutomation() *SyncBuilder {
+	b.op.InitiatedBy = v1alpha1.OperationInitiator{Automated: true}
+	return b
+}
+
+// Build validates the operation against the given application and returns a copy of it. Excluded resources
+// are resolved against the resources of the application status into the list of resources to sync, as the
+// argocd CLI does.
+func (b *SyncBuilder) Build(app *v1alpha1.Application) (*v1alpha1.Operation, error) {
+	op := b.op.DeepCopy()
+	if len(b.exclude) > 0 {
+		resources, err := excludeResources(app, op.Sync.Resources, b.exclude)
+		if err != nil {
+			return nil, err
+		}
+		op.Sync.Resources = resources
+	}
+	if err := Validate(app, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// excludeResources returns the included resources that are not excluded. Without included resources, the
+// non-hook resources of the application status are included.
+func excludeResources(app *v1alpha1.Application, included, excluded []v1alpha1.SyncOperationResource) ([]v1alpha1.SyncOperationResource, error) {
+	if len(included) == 0 {
+		for _, res := range app.Status.Resources {
+			if !res.Hook {
+				included = append(included, syncOperationResource(res))
+			}
+		}
+	}
+	var resources []v1alpha1.SyncOperationResource
+	for _, res := range included {
+		if !slices.Contains(excluded, res) {
+			resources = append(resources, res)
+		}
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("the sync of application %s excludes all of its resources", app.Name)
+	}
+	return resources, nil
+}
+
+// syncOperationResource returns the sync operation resource of a resource of the application status
+func syncOperationResource(res v1alpha1.ResourceStatus) v1alpha1.SyncOperationResource {
+	return v1alpha1.SyncOperationResource{Group: res.Group, Kind: res.Kind, Name: res.Name, Namespace: res.Namespace}
+}
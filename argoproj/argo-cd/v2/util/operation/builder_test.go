@@ -0,0 +1,210 @@
+package operation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newApp() *v1alpha1.Application {
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "argocd"}}
+	app.Spec.Source = &v1alpha1.ApplicationSource{RepoURL: "https://git.example.com/app.git", Path: "app"}
+	app.Status.Resources = []v1alpha1.ResourceStatus{
+		{Kind: "ConfigMap", Namespace: "n", Name: "a"},
+		{Kind: "ConfigMap", Namespace: "n", Name: "b"},
+		{Group: "apps", Kind: "Deployment", Namespace: "n", Name: "a"},
+		{Group: "batch", Kind: "Job", Namespace: "n", Name: "migrate", Hook: true},
+	}
+	return app
+}
+
+// marshal returns the JSON the operation is sent to the API server as
+func marshal(t *testing.T, op *v1alpha1.Operation) string {
+	t.Helper()
+	data, err := json.Marshal(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSyncBuilderExcludeResources(t *testing.T) {
+	configMap := func(name string) v1alpha1.SyncOperationResource {
+		return v1alpha1.SyncOperationResource{Kind: "ConfigMap", Namespace: "n", Name: name}
+	}
+	for _, tc := range []struct {
+		name     string
+		builder  *SyncBuilder
+		expected string
+		err      bool
+	}{
+		{
+			name:     "exclusions only",
+			builder:  NewSync().ExcludeResources(configMap("a")),
+			expected: `{"sync":{"resources":[{"kind":"ConfigMap","name":"b","namespace":"n"},{"group":"apps","kind":"Deployment","name":"a","namespace":"n"}]},"initiatedBy":{},"retry":{}}`,
+		},
+		{
+			name:     "exclusion passed to WithResources",
+			builder:  NewSync().WithResources(v1alpha1.SyncOperationResource{Kind: "ConfigMap", Namespace: "n", Name: "a", Exclude: true}),
+			expected: `{"sync":{"resources":[{"kind":"ConfigMap","name":"b","namespace":"n"},{"group":"apps","kind":"Deployment","name":"a","namespace":"n"}]},"initiatedBy":{},"retry":{}}`,
+		},
+		{
+			name:     "inclusions and exclusions",
+			builder:  NewSync().WithResources(configMap("a"), configMap("b")).ExcludeResources(configMap("a")),
+			expected: `{"sync":{"resources":[{"kind":"ConfigMap","name":"b","namespace":"n"}]},"initiatedBy":{},"retry":{}}`,
+		},
+		{
+			name:    "all resources excluded",
+			builder: NewSync().WithResources(configMap("a")).ExcludeResources(configMap("a")),
+			err:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			op, err := tc.builder.Build(newApp())
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", marshal(t, op))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data := marshal(t, op); data != tc.expected {
+				t.Errorf("unexpected operation\n got: %s\nwant: %s", data, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsExclusions(t *testing.T) {
+	op := &v1alpha1.Operation{Sync: &v1alpha1.SyncOperation{Resources: []v1alpha1.SyncOperationResource{
+		{Kind: "ConfigMap", Namespace: "n", Name: "a"},
+		{Kind: "ConfigMap", Namespace: "n", Name: "a", Exclude: true},
+	}}}
+	if err := Validate(newApp(), op); err == nil {
+		t.Error("expected excluded resources to be rejected")
+	}
+}
+
+func TestSyncBuilder(t *testing.T) {
+	multiSource := newApp()
+	multiSource.Spec.Source = nil
+	multiSource.Spec.Sources = v1alpha1.ApplicationSources{
+		{RepoURL: "https://charts.example.com", Chart: "app", TargetRevision: "1.0.0"},
+		{RepoURL: "https://git.example.com/values.git", Ref: "values"},
+	}
+	for _, tc := range []struct {
+		name     string
+		app      *v1alpha1.Application
+		builder  *SyncBuilder
+		expected string
+		err      bool
+	}{
+		{
+			name:     "full sync",
+			builder:  NewSync(),
+			expected: `{"sync":{},"initiatedBy":{},"retry":{}}`,
+		},
+		{
+			name: "sync with options",
+			builder: NewSync().
+				WithRevision("main").
+				WithPrune(true).
+				WithDryRun(true).
+				WithHookStrategy(true).
+				WithSyncOptions("Validate=false", "Validate=false", "ServerSideApply=true").
+				WithRetry(v1alpha1.RetryStrategy{Limit: 3}).
+				WithInfo("reason", "release").
+				InitiatedBy("admin"),
+			expected: `{"sync":{"revision":"main","prune":true,"dryRun":true,"syncStrategy":{"hook":{"force":true}},"syncOptions":["Validate=false","ServerSideApply=true"]},"initiatedBy":{"username":"admin"},"info":[{"name":"reason","value":"release"}],"retry":{"limit":3}}`,
+		},
+		{
+			name:     "selective sync",
+			builder:  NewSync().WithResources(v1alpha1.SyncOperationResource{Group: "apps", Kind: "Deployment", Namespace: "n", Name: "a"}).InitiatedByAutomation(),
+			expected: `{"sync":{"resources":[{"group":"apps","kind":"Deployment","name":"a","namespace":"n"}]},"initiatedBy":{"automated":true},"retry":{}}`,
+		},
+		{
+			name:     "multi-source sync",
+			app:      multiSource,
+			builder:  NewSync().WithRevisions("1.1.0", "main"),
+			expected: `{"sync":{"revisions":["1.1.0","main"]},"initiatedBy":{},"retry":{}}`,
+		},
+		{
+			name:    "revision of a multi-source application",
+			app:     multiSource,
+			builder: NewSync().WithRevision("main"),
+			err:     true,
+		},
+		{
+			name:    "revisions of a single source application",
+			builder: NewSync().WithRevisions("main"),
+			err:     true,
+		},
+		{
+			name:    "resource listed twice",
+			builder: NewSync().WithResources(v1alpha1.SyncOperationResource{Kind: "ConfigMap", Name: "a"}, v1alpha1.SyncOperationResource{Kind: "ConfigMap", Name: "a"}),
+			err:     true,
+		},
+		{
+			name:    "invalid sync option",
+			builder: NewSync().WithSyncOptions("Validate"),
+			err:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := tc.app
+			if app == nil {
+				app = newApp()
+			}
+			op, err := tc.builder.Build(app)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", marshal(t, op))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data := marshal(t, op); data != tc.expected {
+				t.Errorf("unexpected operation\n got: %s\nwant: %s", data, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	app := newApp()
+	app.Status.History = v1alpha1.RevisionHistories{
+		{ID: 1, Revision: "abc", Source: v1alpha1.ApplicationSource{RepoURL: "https://git.example.com/app.git", Path: "app", TargetRevision: "v1"}},
+		{ID: 2, Revision: "def"},
+	}
+
+	b, err := NewRollback(app, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := b.WithPrune(true).Build(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"sync":{"revision":"abc","prune":true,"syncStrategy":{"apply":{}},"source":{"repoURL":"https://git.example.com/app.git","path":"app","targetRevision":"v1"}},"initiatedBy":{},"retry":{}}`
+	if data := marshal(t, op); data != expected {
+		t.Errorf("unexpected operation\n got: %s\nwant: %s", data, expected)
+	}
+
+	if _, err := NewRollback(app, 2); err == nil {
+		t.Error("expected an error for a history entry without source")
+	}
+	if _, err := NewRollback(app, 3); err == nil {
+		t.Error("expected an error for a missing history entry")
+	}
+
+	app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{}}
+	if _, err := b.Build(app); err == nil {
+		t.Error("expected an error for a rollback of an application with auto-sync")
+	}
+}
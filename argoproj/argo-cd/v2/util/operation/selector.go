@@ -0,0 +1,163 @@
+package operation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const (
+	selectorExcludePrefix      = "!"
+	selectorFieldDelimiter     = ":"
+	selectorNamespaceDelimiter = "/"
+)
+
+// ResourceSelector selects resources of an application with the syntax of the argocd CLI --resource flag:
+// GROUP:KIND:NAME or GROUP:KIND:NAMESPACE/NAME, with an empty group for core resources. Each field may be a
+// glob pattern such as *, and a leading ! excludes the selected resources from the sync.
+type ResourceSelector struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+	// Exclude is true for selectors prefixed with !
+	Exclude bool
+
+	group, kind, namespace, name glob.Glob
+}
+
+// ParseResourceSelector parses a single selector. A selector without namespace matches resources in all
+// namespaces.
+func ParseResourceSelector(selector string) (*ResourceSelector, error) {
+	s := &ResourceSelector{}
+	value := strings.TrimSpace(selector)
+	if strings.HasPrefix(value, selectorExcludePrefix) {
+		value = strings.TrimPrefix(value, selectorExcludePrefix)
+		s.Exclude = true
+	}
+	fields := strings.Split(value, selectorFieldDelimiter)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("resource selector should have GROUP:KIND:NAME or GROUP:KIND:NAMESPACE/NAME, but instead got: %s", selector)
+	}
+	s.Group, s.Kind, s.Name = fields[0], fields[1], fields[2]
+	if namespace, name, ok := strings.Cut(s.Name, selectorNamespaceDelimiter); ok {
+		s.Namespace, s.Name = namespace, name
+	}
+	if s.Kind == "" || s.Name == "" {
+		return nil, fmt.Errorf("resource selector %q: kind and name are required", selector)
+	}
+	if err := s.compile(); err != nil {
+		return nil, fmt.Errorf("resource selector %q: %w", selector, err)
+	}
+	return s, nil
+}
+
+// ParseResourceSelectors parses a list of selectors
+func ParseResourceSelectors(selectors []string) ([]*ResourceSelector, error) {
+	var parsed []*ResourceSelector
+	for _, selector := range selectors {
+		s, err := ParseResourceSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, s)
+	}
+	return parsed, nil
+}
+
+func (s *ResourceSelector) compile() error {
+	var err error
+	if s.group, err = glob.Compile(s.Group); err != nil {
+		return fmt.Errorf("invalid group %q: %w", s.Group, err)
+	}
+	if s.kind, err = glob.Compile(s.Kind); err != nil {
+		return fmt.Errorf("invalid kind %q: %w", s.Kind, err)
+	}
+	if s.Namespace != "" {
+		if s.namespace, err = glob.Compile(s.Namespace); err != nil {
+			return fmt.Errorf("invalid namespace %q: %w", s.Namespace, err)
+		}
+	}
+	if s.name, err = glob.Compile(s.Name); err != nil {
+		return fmt.Errorf("invalid name %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// Matches returns true if the selector selects the given resource, regardless of Exclude
+func (s *ResourceSelector) Matches(group, kind, namespace, name string) bool {
+	return s.group.Match(group) &&
+		s.kind.Match(kind) &&
+		(s.namespace == nil || s.namespace.Match(namespace)) &&
+		s.name.Match(name)
+}
+
+// String formats the selector in the syntax ParseResourceSelector accepts
+func (s *ResourceSelector) String() string {
+	return formatSelector(s.Group, s.Kind, s.Namespace, s.Name, s.Exclude)
+}
+
+// FormatResourceSelector formats a sync operation resource as selector
+func FormatResourceSelector(res v1alpha1.SyncOperationResource) string {
+	return formatSelector(res.Group, res.Kind, res.Namespace, res.Name, res.Exclude)
+}
+
+func formatSelector(group, kind, namespace, name string, exclude bool) string {
+	b := &strings.Builder{}
+	if exclude {
+		b.WriteString(selectorExcludePrefix)
+	}
+	b.WriteString(group + selectorFieldDelimiter + kind + selectorFieldDelimiter)
+	if namespace != "" {
+		b.WriteString(namespace + selectorNamespaceDelimiter)
+	}
+	b.WriteString(name)
+	return b.String()
+}
+
+// ExpandResourceSelectors resolves selectors against the resources of the application status into the
+// resources to sync. A resource is synced if it is not selected by an exclusion and, if there are inclusions,
+// selected by one of them. Hooks are never selected. Selectors that match no resource and selectors that
+// exclude all resources are an error. Without selectors, nil is returned, which syncs all resources.
+func ExpandResourceSelectors(app *v1alpha1.Application, selectors []*ResourceSelector) ([]v1alpha1.SyncOperationResource, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	matched := make([]bool, len(selectors))
+	hasInclusions := false
+	for _, s := range selectors {
+		hasInclusions = hasInclusions || !s.Exclude
+	}
+	var resources []v1alpha1.SyncOperationResource
+	for _, res := range app.Status.Resources {
+		if res.Hook {
+			continue
+		}
+		included, excluded := !hasInclusions, false
+		for i, s := range selectors {
+			if !s.Matches(res.Group, res.Kind, res.Namespace, res.Name) {
+				continue
+			}
+			matched[i] = true
+			if s.Exclude {
+				excluded = true
+			} else {
+				included = true
+			}
+		}
+		if included && !excluded {
+			resources = append(resources, syncOperationResource(res))
+		}
+	}
+	for i, s := range selectors {
+		if !matched[i] {
+			return nil, fmt.Errorf("resource selector %s does not match any resource of application %s", s, app.Name)
+		}
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("resource selectors exclude all resources of application %s", app.Name)
+	}
+	return resources, nil
+}
@@ -0,0 +1,109 @@
+package operation
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpandResourceSelectors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		selectors []string
+		expected  []string
+		err       bool
+	}{
+		{
+			name:      "include only",
+			selectors: []string{":ConfigMap:*"},
+			expected:  []string{":ConfigMap:n/a", ":ConfigMap:n/b"},
+		},
+		{
+			name:      "include with namespace",
+			selectors: []string{"apps:Deployment:n/a"},
+			expected:  []string{"apps:Deployment:n/a"},
+		},
+		{
+			name:      "exclude only",
+			selectors: []string{"!:ConfigMap:a"},
+			expected:  []string{":ConfigMap:n/b", "apps:Deployment:n/a"},
+		},
+		{
+			name:      "mixed",
+			selectors: []string{":ConfigMap:*", "!:ConfigMap:b"},
+			expected:  []string{":ConfigMap:n/a"},
+		},
+		{
+			name:      "exclusion takes precedence",
+			selectors: []string{"*:*:a", "!apps:*:*"},
+			expected:  []string{":ConfigMap:n/a"},
+		},
+		{
+			name:      "hooks are never selected",
+			selectors: []string{"batch:Job:migrate"},
+			err:       true,
+		},
+		{
+			name:      "hooks are not synced by exclusions",
+			selectors: []string{"!apps:Deployment:a", "!:ConfigMap:*"},
+			err:       true,
+		},
+		{
+			name:      "selector without match",
+			selectors: []string{":Secret:*"},
+			err:       true,
+		},
+		{
+			name: "no selectors",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			selectors, err := ParseResourceSelectors(tc.selectors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resources, err := ExpandResourceSelectors(newApp(), selectors)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", resources)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var formatted []string
+			for _, res := range resources {
+				if res.Exclude {
+					t.Errorf("unexpected excluded resource %v", res)
+				}
+				formatted = append(formatted, FormatResourceSelector(res))
+			}
+			if !slices.Equal(formatted, tc.expected) {
+				t.Errorf("unexpected resources %v", formatted)
+			}
+		})
+	}
+}
+
+func TestParseResourceSelector(t *testing.T) {
+	for _, tc := range []struct {
+		selector string
+		err      bool
+	}{
+		{selector: ":ConfigMap:a"},
+		{selector: "!apps:Deployment:n/a"},
+		{selector: "apps:Deployment", err: true},
+		{selector: ":ConfigMap:", err: true},
+		{selector: ":ConfigMap:[", err: true},
+	} {
+		s, err := ParseResourceSelector(tc.selector)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%s: expected an error", tc.selector)
+		case !tc.err && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.selector, err)
+		case err == nil && s.String() != tc.selector:
+			t.Errorf("%s: formatted as %s", tc.selector, s)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// Validate checks that op can be run for app. It does not check whether another operation is in progress.
+func Validate(app *v1alpha1.Application, op *v1alpha1.Operation) error {
+	sync := op.Sync
+	if sync == nil {
+		return errors.New("operation has no sync parameters")
+	}
+
+	if sync.Source != nil && len(sync.Sources) > 0 {
+		return errors.New("sync cannot override both source and sources")
+	}
+	rollback := sync.Source != nil || len(sync.Sources) > 0
+	if rollback && app.Spec.SyncPolicy.IsAutomatedSyncEnabled() {
+		return fmt.Errorf("rollback cannot be initiated when auto-sync is enabled for application %s", app.Name)
+	}
+
+	sources := len(app.Spec.GetSources())
+	multiSource := app.Spec.HasMultipleSources()
+	if sync.Source != nil {
+		sources, multiSource = 1, false
+	} else if len(sync.Sources) > 0 {
+		sources, multiSource = len(sync.Sources), true
+	}
+	if sources == 0 && len(sync.Manifests) == 0 {
+		return fmt.Errorf("application %s has no source", app.Name)
+	}
+	if multiSource {
+		if sync.Revision != "" && len(sync.Revisions) == 0 {
+			return errors.New("revision cannot be used with multiple sources, use revisions instead")
+		}
+		if len(sync.Revisions) > 0 && len(sync.Revisions) != sources {
+			return fmt.Errorf("got %d revisions for %d sources", len(sync.Revisions), sources)
+		}
+	} else if len(sync.Revisions) > 0 {
+		return errors.New("revisions can only be used with multiple sources, use revision instead")
+	}
+
+	if strategy := sync.SyncStrategy; strategy != nil && strategy.Apply != nil && strategy.Hook != nil {
+		return errors.New("sync strategy cannot be both apply and hook")
+	}
+	for _, option := range sync.SyncOptions {
+		if key, value, ok := strings.Cut(option, "="); !ok || key == "" || value == "" {
+			return fmt.Errorf("invalid sync option %q, expected key=value", option)
+		}
+	}
+
+	seen := map[v1alpha1.SyncOperationResource]bool{}
+	for i, res := range sync.Resources {
+		if res.Kind == "" || res.Name == "" {
+			return fmt.Errorf("resources[%d]: kind and name are required", i)
+		}
+		if res.Exclude {
+			// Exclude is not serialized, the API server would sync the resource instead of skipping it
+			return fmt.Errorf("resources[%d]: %s/%s %s is excluded, list the resources to sync instead", i, res.Group, res.Kind, res.Name)
+		}
+		if seen[res] {
+			return fmt.Errorf("resources[%d]: %s/%s %s is listed more than once", i, res.Group, res.Kind, res.Name)
+		}
+		seen[res] = true
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package refresh builds the patches that request a refresh or hydration of an Application through the
+// annotations the Argo CD controller watches.
+package refresh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RefreshPatch returns a JSON merge patch that requests a refresh of the given type. A pending hard refresh
+// is replaced, so use RefreshPatchFor to avoid downgrading it.
+func RefreshPatch(refreshType v1alpha1.RefreshType) ([]byte, error) {
+	if refreshType != v1alpha1.RefreshTypeNormal && refreshType != v1alpha1.RefreshTypeHard {
+		return nil, fmt.Errorf("unknown refresh type %q", refreshType)
+	}
+	return annotationPatch(v1alpha1.AnnotationKeyRefresh, string(refreshType))
+}
+
+// RefreshPatchFor returns a JSON merge patch that requests a refresh of app with the given type, keeping a
+// pending hard refresh
+func RefreshPatchFor(app *v1alpha1.Application, refreshType v1alpha1.RefreshType) ([]byte, error) {
+	if pending, ok := app.IsRefreshRequested(); ok && pending == v1alpha1.RefreshTypeHard {
+		refreshType = v1alpha1.RefreshTypeHard
+	}
+	return RefreshPatch(refreshType)
+}
+
+// ClearRefreshPatch returns a JSON merge patch that withdraws a pending refresh request
+func ClearRefreshPatch() ([]byte, error) {
+	return annotationPatch(v1alpha1.AnnotationKeyRefresh, nil)
+}
+
+// HydratePatch returns a JSON merge patch that requests a hydration of the given type
+func HydratePatch(hydrateType v1alpha1.HydrateType) ([]byte, error) {
+	if hydrateType != v1alpha1.HydrateTypeNormal {
+		return nil, fmt.Errorf("unknown hydrate type %q", hydrateType)
+	}
+	return annotationPatch(v1alpha1.AnnotationKeyHydrate, string(hydrateType))
+}
+
+// ClearHydratePatch returns a JSON merge patch that withdraws a pending hydration request
+func ClearHydratePatch() ([]byte, error) {
+	return annotationPatch(v1alpha1.AnnotationKeyHydrate, nil)
+}
+
+// RefreshClientPatch returns RefreshPatch as controller-runtime patch
+func RefreshClientPatch(refreshType v1alpha1.RefreshType) (client.Patch, error) {
+	return clientPatch(RefreshPatch(refreshType))
+}
+
+// HydrateClientPatch returns HydratePatch as controller-runtime patch
+func HydrateClientPatch(hydrateType v1alpha1.HydrateType) (client.Patch, error) {
+	return clientPatch(HydratePatch(hydrateType))
+}
+
+// RequestRefresh patches app to request a refresh of the given type, keeping a pending hard refresh. app is
+// updated with the patched object.
+func RequestRefresh(ctx context.Context, c client.Client, app *v1alpha1.Application, refreshType v1alpha1.RefreshType) error {
+	patch, err := clientPatch(RefreshPatchFor(app, refreshType))
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(ctx, app, patch); err != nil {
+		return fmt.Errorf("failed to request %s refresh of application %s/%s: %w", refreshType, app.Namespace, app.Name, err)
+	}
+	return nil
+}
+
+// RequestHydrate patches app to request a hydration. app is updated with the patched object.
+func RequestHydrate(ctx context.Context, c client.Client, app *v1alpha1.Application) error {
+	patch, err := HydrateClientPatch(v1alpha1.HydrateTypeNormal)
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(ctx, app, patch); err != nil {
+		return fmt.Errorf("failed to request hydration of application %s/%s: %w", app.Namespace, app.Name, err)
+	}
+	return nil
+}
+
+// annotationPatch returns a merge patch that sets the annotation to value, or removes it if value is nil
+func annotationPatch(key string, value any) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{key: value},
+		},
+	})
+}
+
+func clientPatch(data []byte, err error) (client.Patch, error) {
+	if err != nil {
+		return nil, err
+	}
+	return client.RawPatch(types.MergePatchType, data), nil
+}
@@ -0,0 +1,321 @@
+// Package specdiff compares the specs of Applications and AppProjects field by field, for human readable
+// summaries of changes.
+package specdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// Category groups changes by the part of the spec they affect
+type Category string
+
+const (
+	CategorySource            Category = "source"
+	CategoryDestination       Category = "destination"
+	CategorySyncPolicy        Category = "syncPolicy"
+	CategoryIgnoreDifferences Category = "ignoreDifferences"
+	CategoryOther             Category = "other"
+)
+
+// ChangeType is the kind of a change
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// Change is a changed field. Old and New hold JSON values: strings, float64 numbers, bools, nil, []any and
+// map[string]any.
+type Change struct {
+	// Path is the path of the field relative to the spec, such as source.helm.values.image.tag
+	Path     string
+	Category Category
+	Type     ChangeType
+	// Old is the old value, nil if the field was added
+	Old any
+	// New is the new value, nil if the field was removed
+	New any
+}
+
+// String describes the change in one line
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("added %s: %s", c.Path, render(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("removed %s: %s", c.Path, render(c.Old))
+	default:
+		return fmt.Sprintf("modified %s: %s -> %s", c.Path, render(c.Old), render(c.New))
+	}
+}
+
+// applicationCategories maps the top level fields of an ApplicationSpec to categories
+var applicationCategories = map[string]Category{
+	"source":            CategorySource,
+	"sources":           CategorySource,
+	"sourceHydrator":    CategorySource,
+	"destination":       CategoryDestination,
+	"syncPolicy":        CategorySyncPolicy,
+	"ignoreDifferences": CategoryIgnoreDifferences,
+}
+
+// projectCategories maps the top level fields of an AppProjectSpec to categories
+var projectCategories = map[string]Category{
+	"sourceRepos":                CategorySource,
+	"sourceNamespaces":           CategorySource,
+	"signatureKeys":              CategorySource,
+	"destinations":               CategoryDestination,
+	"destinationServiceAccounts": CategoryDestination,
+	"syncWindows":                CategorySyncPolicy,
+}
+
+// DiffApplicationSpecs returns the changes from oldSpec to newSpec ordered by path. Helm values are compared
+// semantically: the effective values of a source, ValuesObject or else the parsed Values YAML, are compared
+// as objects under the path helm.values, so reformatting values or moving them between Values and
+// ValuesObject is not a change. Sources are matched by repoURL, path and chart, and ignoreDifferences rules by
+// group, kind and name, so inserting an item is reported as a single change. Lists with ambiguous items are
+// compared by index.
+func DiffApplicationSpecs(oldSpec, newSpec *v1alpha1.ApplicationSpec) ([]Change, error) {
+	oldValue, err := normalizeApplicationSpec(oldSpec)
+	if err != nil {
+		return nil, fmt.Errorf("old spec: %w", err)
+	}
+	newValue, err := normalizeApplicationSpec(newSpec)
+	if err != nil {
+		return nil, fmt.Errorf("new spec: %w", err)
+	}
+	return diff(oldValue, newValue, applicationCategories), nil
+}
+
+// DiffAppProjectSpecs returns the changes from oldSpec to newSpec ordered by path
+func DiffAppProjectSpecs(oldSpec, newSpec *v1alpha1.AppProjectSpec) ([]Change, error) {
+	oldValue, err := toJSONValue(oldSpec)
+	if err != nil {
+		return nil, fmt.Errorf("old spec: %w", err)
+	}
+	newValue, err := toJSONValue(newSpec)
+	if err != nil {
+		return nil, fmt.Errorf("new spec: %w", err)
+	}
+	return diff(oldValue, newValue, projectCategories), nil
+}
+
+func diff(oldValue, newValue map[string]any, categories map[string]Category) []Change {
+	var changes []Change
+	for _, key := range unionKeys(oldValue, newValue) {
+		category, ok := categories[key]
+		if !ok {
+			category = CategoryOther
+		}
+		compare(key, category, oldValue[key], newValue[key], &changes)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// compare appends the changes between the two values at path, descending into maps and lists
+func compare(path string, category Category, oldValue, newValue any, changes *[]Change) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		*changes = append(*changes, Change{Path: path, Category: category, Type: ChangeAdded, New: newValue})
+		return
+	case newValue == nil:
+		*changes = append(*changes, Change{Path: path, Category: category, Type: ChangeRemoved, Old: oldValue})
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if oldIsMap && newIsMap {
+		for _, key := range unionKeys(oldMap, newMap) {
+			compare(joinPath(path, key), category, oldMap[key], newMap[key], changes)
+		}
+		return
+	}
+	oldList, oldIsList := oldValue.([]any)
+	newList, newIsList := newValue.([]any)
+	if oldIsList && newIsList {
+		if identify, ok := listIdentities[path]; ok && compareByIdentity(path, category, oldList, newList, identify, changes) {
+			return
+		}
+		for i := 0; i < max(len(oldList), len(newList)); i++ {
+			var o, n any
+			if i < len(oldList) {
+				o = oldList[i]
+			}
+			if i < len(newList) {
+				n = newList[i]
+			}
+			compare(fmt.Sprintf("%s[%d]", path, i), category, o, n, changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, Change{Path: path, Category: category, Type: ChangeModified, Old: oldValue, New: newValue})
+	}
+}
+
+// listIdentities identifies the items of lists at the given paths, so inserting or removing an item does not
+// show up as a change of all items after it
+var listIdentities = map[string]func(item map[string]any) string{
+	"sources": func(source map[string]any) string {
+		return fmt.Sprint(source["repoURL"], "|", source["path"], "|", source["chart"])
+	},
+	"ignoreDifferences": func(rule map[string]any) string {
+		return fmt.Sprint(rule["group"], "|", rule["kind"], "|", rule["name"])
+	},
+}
+
+// compareByIdentity compares the items of two lists with the same identity and appends the changes. Changed
+// items are reported under their new index, removed ones under their old index. It returns false without
+// comparing if the identities are not unique, in which case the lists are compared by index.
+func compareByIdentity(path string, category Category, oldList, newList []any, identify func(map[string]any) string, changes *[]Change) bool {
+	oldIndex, ok := indexByIdentity(oldList, identify)
+	if !ok {
+		return false
+	}
+	newIndex, ok := indexByIdentity(newList, identify)
+	if !ok {
+		return false
+	}
+	for i, item := range newList {
+		var o any
+		if j, ok := oldIndex[identify(item.(map[string]any))]; ok {
+			o = oldList[j]
+		}
+		compare(fmt.Sprintf("%s[%d]", path, i), category, o, item, changes)
+	}
+	for i, item := range oldList {
+		if _, ok := newIndex[identify(item.(map[string]any))]; !ok {
+			compare(fmt.Sprintf("%s[%d]", path, i), category, item, nil, changes)
+		}
+	}
+	return true
+}
+
+// indexByIdentity maps the identities of the items of list to their index. It returns false if an item is
+// not an object or an identity is not unique.
+func indexByIdentity(list []any, identify func(map[string]any) string) (map[string]int, bool) {
+	index := make(map[string]int, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		id := identify(m)
+		if _, duplicate := index[id]; duplicate {
+			return nil, false
+		}
+		index[id] = i
+	}
+	return index, true
+}
+
+// normalizeApplicationSpec converts spec to a JSON value with the helm values of all sources replaced by
+// their effective values
+func normalizeApplicationSpec(spec *v1alpha1.ApplicationSpec) (map[string]any, error) {
+	value, err := toJSONValue(spec)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return value, nil
+	}
+	if spec.Source != nil {
+		if err := normalizeHelmValues(value["source"], spec.Source); err != nil {
+			return nil, fmt.Errorf("source: %w", err)
+		}
+	}
+	sources, _ := value["sources"].([]any)
+	for i := range spec.Sources {
+		if i < len(sources) {
+			if err := normalizeHelmValues(sources[i], &spec.Sources[i]); err != nil {
+				return nil, fmt.Errorf("sources[%d]: %w", i, err)
+			}
+		}
+	}
+	return value, nil
+}
+
+func normalizeHelmValues(sourceValue any, source *v1alpha1.ApplicationSource) error {
+	sourceMap, _ := sourceValue.(map[string]any)
+	helm := source.Helm
+	if sourceMap == nil || helm == nil {
+		return nil
+	}
+	helmValue, ok := sourceMap["helm"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	delete(helmValue, "valuesObject")
+	delete(helmValue, "values")
+
+	// empty values are absent values, no matter if set as empty YAML or as empty object
+	if helm.ValuesIsEmpty() {
+		if len(helmValue) == 0 {
+			delete(sourceMap, "helm")
+		}
+		return nil
+	}
+	var values any
+	if err := yaml.Unmarshal(helm.ValuesYAML(), &values); err != nil {
+		return fmt.Errorf("invalid helm values: %w", err)
+	}
+	helmValue["values"] = values
+	return nil
+}
+
+func toJSONValue(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	value := map[string]any{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// joinPath appends key to path, quoting keys that are not plain identifiers, such as helm values with dots
+func joinPath(path, key string) string {
+	if !plainKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
+
+func render(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,106 @@
+package specdiff
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func changeStrings(t *testing.T, oldSpec, newSpec *v1alpha1.ApplicationSpec) []string {
+	t.Helper()
+	changes, err := DiffApplicationSpecs(oldSpec, newSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, c := range changes {
+		result = append(result, c.String())
+	}
+	return result
+}
+
+func TestDiffApplicationSpecsHelmValues(t *testing.T) {
+	source := func(helm *v1alpha1.ApplicationSourceHelm) *v1alpha1.ApplicationSpec {
+		return &v1alpha1.ApplicationSpec{Source: &v1alpha1.ApplicationSource{RepoURL: "https://charts.example.com", Chart: "app", Helm: helm}}
+	}
+	for _, tc := range []struct {
+		name     string
+		old, new *v1alpha1.ApplicationSpec
+		expected []string
+	}{
+		{
+			name: "empty values to empty values object",
+			old:  source(&v1alpha1.ApplicationSourceHelm{Values: ""}),
+			new:  source(&v1alpha1.ApplicationSourceHelm{ValuesObject: &runtime.RawExtension{Raw: []byte(`{}`)}}),
+		},
+		{
+			name: "no helm to empty values object",
+			old:  source(nil),
+			new:  source(&v1alpha1.ApplicationSourceHelm{ValuesObject: &runtime.RawExtension{Raw: []byte(`{}`)}}),
+		},
+		{
+			name: "values to equal values object",
+			old:  source(&v1alpha1.ApplicationSourceHelm{Values: "image:\n  tag: v1\n"}),
+			new:  source(&v1alpha1.ApplicationSourceHelm{ValuesObject: &runtime.RawExtension{Raw: []byte(`{"image":{"tag":"v1"}}`)}}),
+		},
+		{
+			name:     "changed value",
+			old:      source(&v1alpha1.ApplicationSourceHelm{Values: "image:\n  tag: v1\n"}),
+			new:      source(&v1alpha1.ApplicationSourceHelm{ValuesObject: &runtime.RawExtension{Raw: []byte(`{"image":{"tag":"v2"}}`)}}),
+			expected: []string{`modified source.helm.values.image.tag: "v1" -> "v2"`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if changes := changeStrings(t, tc.old, tc.new); !slices.Equal(changes, tc.expected) {
+				t.Errorf("unexpected changes %q", changes)
+			}
+		})
+	}
+}
+
+func TestDiffApplicationSpecsLists(t *testing.T) {
+	chart := v1alpha1.ApplicationSource{RepoURL: "https://charts.example.com", Chart: "app", TargetRevision: "1.0.0"}
+	values := v1alpha1.ApplicationSource{RepoURL: "https://git.example.com/values.git", Ref: "values"}
+	manifests := v1alpha1.ApplicationSource{RepoURL: "https://git.example.com/manifests.git", Path: "app"}
+	replicas := v1alpha1.ResourceIgnoreDifferences{Group: "apps", Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}}
+	caBundle := v1alpha1.ResourceIgnoreDifferences{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration", JSONPointers: []string{"/webhooks/0/clientConfig/caBundle"}}
+
+	for _, tc := range []struct {
+		name     string
+		old, new *v1alpha1.ApplicationSpec
+		expected []string
+	}{
+		{
+			name:     "source inserted at the front",
+			old:      &v1alpha1.ApplicationSpec{Sources: v1alpha1.ApplicationSources{chart, values}},
+			new:      &v1alpha1.ApplicationSpec{Sources: v1alpha1.ApplicationSources{manifests, chart, values}},
+			expected: []string{`added sources[0]: {"path":"app","repoURL":"https://git.example.com/manifests.git"}`},
+		},
+		{
+			name:     "source removed from the front and another changed",
+			old:      &v1alpha1.ApplicationSpec{Sources: v1alpha1.ApplicationSources{manifests, chart, values}},
+			new:      &v1alpha1.ApplicationSpec{Sources: v1alpha1.ApplicationSources{{RepoURL: chart.RepoURL, Chart: chart.Chart, TargetRevision: "2.0.0"}, values}},
+			expected: []string{`removed sources[0]: {"path":"app","repoURL":"https://git.example.com/manifests.git"}`, `modified sources[0].targetRevision: "1.0.0" -> "2.0.0"`},
+		},
+		{
+			name:     "ignore difference inserted at the front",
+			old:      &v1alpha1.ApplicationSpec{IgnoreDifferences: v1alpha1.IgnoreDifferences{replicas}},
+			new:      &v1alpha1.ApplicationSpec{IgnoreDifferences: v1alpha1.IgnoreDifferences{caBundle, replicas}},
+			expected: []string{`added ignoreDifferences[0]: {"group":"admissionregistration.k8s.io","jsonPointers":["/webhooks/0/clientConfig/caBundle"],"kind":"MutatingWebhookConfiguration"}`},
+		},
+		{
+			name:     "ambiguous items are compared by index",
+			old:      &v1alpha1.ApplicationSpec{IgnoreDifferences: v1alpha1.IgnoreDifferences{replicas, replicas}},
+			new:      &v1alpha1.ApplicationSpec{IgnoreDifferences: v1alpha1.IgnoreDifferences{replicas}},
+			expected: []string{`removed ignoreDifferences[1]: {"group":"apps","jsonPointers":["/spec/replicas"],"kind":"Deployment"}`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if changes := changeStrings(t, tc.old, tc.new); !slices.Equal(changes, tc.expected) {
+				t.Errorf("unexpected changes %q", changes)
+			}
+		})
+	}
+}
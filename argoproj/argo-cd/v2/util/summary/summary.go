@@ -0,0 +1,179 @@
+// Package summary aggregates the resource statuses of an Application into counts, breakdowns and a list of
+// problems, for dashboards and reports.
+package summary
+
+import (
+	"sort"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Counts holds the number of resources per sync and health status
+type Counts struct {
+	// Total is the number of resources
+	Total int
+	// BySyncStatus holds the number of resources per sync status
+	BySyncStatus map[v1alpha1.SyncStatusCode]int
+	// ByHealth holds the number of resources per health status. Resources without health are not counted.
+	ByHealth map[v1alpha1.HealthStatusCode]int
+}
+
+// Problem is a resource that is not synced or not healthy
+type Problem struct {
+	// Resource is the affected resource
+	Resource v1alpha1.ResourceStatus
+	// Health is the health status of the resource, empty if it has none
+	Health v1alpha1.HealthStatusCode
+	// Message is the health message of the resource
+	Message string
+}
+
+// ResourceSummary summarizes the resources of an Application. Hooks are only listed in Hooks and are not
+// counted anywhere else.
+type ResourceSummary struct {
+	Counts
+	// RequiresPruning holds the resources that will be deleted by the next sync with pruning
+	RequiresPruning []v1alpha1.ResourceStatus
+	// RequiresDeletionConfirmation holds the resources that require confirmation before they are deleted
+	RequiresDeletionConfirmation []v1alpha1.ResourceStatus
+	// Hooks holds the hook resources
+	Hooks []v1alpha1.ResourceStatus
+	// ByKind holds the counts per group kind
+	ByKind map[schema.GroupKind]*Counts
+	// ByNamespace holds the counts per namespace. Cluster scoped resources are counted under the empty namespace.
+	ByNamespace map[string]*Counts
+	// Problems holds all resources that are not synced or not healthy, worst first. See TopProblems.
+	Problems []Problem
+}
+
+// Summarize summarizes the resources of the given application status. If the resource health is stored in
+// the application tree, resourceHealth is used to look it up, as with v1alpha1.AggregateHealthStatus.
+func Summarize(status *v1alpha1.ApplicationStatus, resourceHealth func(v1alpha1.ResourceStatus) *v1alpha1.HealthStatus) *ResourceSummary {
+	return SummarizeResources(status.Resources, status.ResourceHealthSource, resourceHealth)
+}
+
+// SummarizeResources summarizes the given resources, see Summarize
+func SummarizeResources(resources []v1alpha1.ResourceStatus, source v1alpha1.ResourceHealthLocation, resourceHealth func(v1alpha1.ResourceStatus) *v1alpha1.HealthStatus) *ResourceSummary {
+	s := &ResourceSummary{
+		Counts:      newCounts(),
+		ByKind:      map[schema.GroupKind]*Counts{},
+		ByNamespace: map[string]*Counts{},
+	}
+	for _, res := range resources {
+		if res.Hook {
+			s.Hooks = append(s.Hooks, res)
+			continue
+		}
+
+		health := res.Health
+		if source == v1alpha1.ResourceHealthLocationAppTree {
+			health = nil
+			if resourceHealth != nil {
+				health = resourceHealth(res)
+			}
+		}
+
+		gk := schema.GroupKind{Group: res.Group, Kind: res.Kind}
+		if s.ByKind[gk] == nil {
+			s.ByKind[gk] = ptr(newCounts())
+		}
+		if s.ByNamespace[res.Namespace] == nil {
+			s.ByNamespace[res.Namespace] = ptr(newCounts())
+		}
+		for _, c := range []*Counts{&s.Counts, s.ByKind[gk], s.ByNamespace[res.Namespace]} {
+			c.add(res.Status, health)
+		}
+
+		if res.RequiresPruning {
+			s.RequiresPruning = append(s.RequiresPruning, res)
+		}
+		if res.RequiresDeletionConfirmation {
+			s.RequiresDeletionConfirmation = append(s.RequiresDeletionConfirmation, res)
+		}
+		if problem, ok := toProblem(res, health); ok {
+			s.Problems = append(s.Problems, problem)
+		}
+	}
+	sortProblems(s.Problems)
+	return s
+}
+
+// TopProblems returns the n worst problems, or all if n is not positive
+func (s *ResourceSummary) TopProblems(n int) []Problem {
+	if n <= 0 || n > len(s.Problems) {
+		return s.Problems
+	}
+	return s.Problems[:n]
+}
+
+func newCounts() Counts {
+	return Counts{
+		BySyncStatus: map[v1alpha1.SyncStatusCode]int{},
+		ByHealth:     map[v1alpha1.HealthStatusCode]int{},
+	}
+}
+
+func (c *Counts) add(syncStatus v1alpha1.SyncStatusCode, health *v1alpha1.HealthStatus) {
+	c.Total++
+	if syncStatus == "" {
+		syncStatus = v1alpha1.SyncStatusCodeUnknown
+	}
+	c.BySyncStatus[syncStatus]++
+	if health != nil && health.Status != "" {
+		c.ByHealth[health.Status]++
+	}
+}
+
+// toProblem returns the problem of a resource that is out of sync, has an unknown sync status or is not
+// healthy. Suspended resources are not considered a problem.
+func toProblem(res v1alpha1.ResourceStatus, health *v1alpha1.HealthStatus) (Problem, bool) {
+	problem := Problem{Resource: res}
+	if health != nil {
+		problem.Health = health.Status
+		problem.Message = health.Message
+	}
+	unhealthy := problem.Health != "" && problem.Health != v1alpha1.HealthStatusHealthy && problem.Health != v1alpha1.HealthStatusSuspended
+	return problem, unhealthy || !res.Status.IsSynced()
+}
+
+// sortProblems orders problems by health severity, then by sync status, then by resource identity, so the
+// order is stable regardless of the order of the resources
+func sortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.Health.Severity() != b.Health.Severity() {
+			return a.Health.Severity() > b.Health.Severity()
+		}
+		if syncRank(a.Resource.Status) != syncRank(b.Resource.Status) {
+			return syncRank(a.Resource.Status) > syncRank(b.Resource.Status)
+		}
+		ra, rb := a.Resource, b.Resource
+		if ra.Group != rb.Group {
+			return ra.Group < rb.Group
+		}
+		if ra.Kind != rb.Kind {
+			return ra.Kind < rb.Kind
+		}
+		if ra.Namespace != rb.Namespace {
+			return ra.Namespace < rb.Namespace
+		}
+		return ra.Name < rb.Name
+	})
+}
+
+// syncRank ranks sync statuses, higher being worse
+func syncRank(c v1alpha1.SyncStatusCode) int {
+	switch {
+	case c.IsOutOfSync():
+		return 2
+	case c.IsUnknown():
+		return 1
+	default:
+		return 0
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
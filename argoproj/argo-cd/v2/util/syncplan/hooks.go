@@ -0,0 +1,42 @@
+package syncplan
+
+import (
+	"strconv"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/util/annotations"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// HookTypes returns the hook types of obj. As in Argo CD, unknown values are ignored.
+func HookTypes(obj *unstructured.Unstructured) []v1alpha1.HookType {
+	types, _ := annotations.HookTypes(obj)
+	return types
+}
+
+// IsHook returns true if obj is a hook. As in Argo CD, a resource whose only hook type is Skip is not a hook,
+// and neither are CRDs with the Helm crd-install hook.
+func IsHook(obj *unstructured.Unstructured) bool {
+	if _, ok := obj.GetAnnotations()[annotations.AnnotationHook]; ok {
+		return !Skip(obj)
+	}
+	value, ok := obj.GetAnnotations()[annotations.AnnotationHelmHook]
+	return ok && value != annotations.HelmHookCRDInstall
+}
+
+// Skip returns true if obj is annotated to never be applied
+func Skip(obj *unstructured.Unstructured) bool {
+	types := HookTypes(obj)
+	return len(types) == 1 && types[0] == v1alpha1.HookTypeSkip
+}
+
+// Wave returns the sync wave of obj. Unlike annotations.SyncWave it does not fail: as in Argo CD, an invalid
+// sync wave falls back to the Helm hook weight and then to 0.
+func Wave(obj *unstructured.Unstructured) int {
+	for _, key := range []string{annotations.AnnotationSyncWave, annotations.AnnotationHelmHookWeight} {
+		if wave, err := strconv.Atoi(obj.GetAnnotations()[key]); err == nil {
+			return wave
+		}
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+// Package syncplan computes the order in which Argo CD applies the resources of a sync operation, so that the
+// rollout ordering of a change can be reviewed before it is synced.
+package syncplan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// kinds is the order in which Argo CD applies resources of the same phase and wave. Kinds that are not listed
+// are applied last.
+var kinds = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"SecretList",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleList",
+	"ClusterRoleBinding",
+	"ClusterRoleBindingList",
+	"Role",
+	"RoleList",
+	"RoleBinding",
+	"RoleBindingList",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
+// kindOrder maps the kinds to negative values, so kinds that are not listed sort last with the zero value
+var kindOrder = map[string]int{}
+
+func init() {
+	for i, kind := range kinds {
+		kindOrder[kind] = i - len(kinds)
+	}
+}
+
+// Task is a resource applied in a specific phase and wave
+type Task struct {
+	// Object is the desired resource
+	Object *unstructured.Unstructured
+	// Phase is the phase the resource is applied in
+	Phase v1alpha1.SyncPhase
+	// Wave is the wave the resource is applied in
+	Wave int
+	// HookType is the hook type the task was created for, empty if the resource is not a hook
+	HookType v1alpha1.HookType
+}
+
+// Step is a wave of a phase, a group of tasks that Argo CD applies together
+type Step struct {
+	// Phase is the phase of the step
+	Phase v1alpha1.SyncPhase
+	// Wave is the wave number
+	Wave int
+	// Tasks holds the tasks of the step in the order they are applied
+	Tasks []Task
+}
+
+// Plan is the ordered execution plan of a sync operation
+type Plan struct {
+	// Steps holds the steps in the order they run. SyncFail steps only run if the sync fails and PostDelete
+	// steps only run when the application is deleted.
+	Steps []Step
+	// Skipped holds the resources annotated to never be applied
+	Skipped []*unstructured.Unstructured
+}
+
+// NewPlan plans the sync of the given desired resources. Hooks with several hook types are planned once per
+// phase, hooks without a known hook type are not planned at all.
+func NewPlan(resources []*unstructured.Unstructured) *Plan {
+	plan := &Plan{}
+	var tasks []Task
+	for _, obj := range resources {
+		if obj == nil {
+			continue
+		}
+		if Skip(obj) {
+			plan.Skipped = append(plan.Skipped, obj)
+			continue
+		}
+		wave := Wave(obj)
+		if !IsHook(obj) {
+			tasks = append(tasks, Task{Object: obj, Phase: v1alpha1.SyncPhaseSync, Wave: wave})
+			continue
+		}
+		for _, hookType := range HookTypes(obj) {
+			if phase, ok := hookType.SyncPhase(); ok {
+				tasks = append(tasks, Task{Object: obj, Phase: phase, Wave: wave, HookType: hookType})
+			}
+		}
+	}
+	sortTasks(tasks)
+
+	for _, task := range tasks {
+		if n := len(plan.Steps); n == 0 || plan.Steps[n-1].Phase != task.Phase || plan.Steps[n-1].Wave != task.Wave {
+			plan.Steps = append(plan.Steps, Step{Phase: task.Phase, Wave: task.Wave})
+		}
+		last := &plan.Steps[len(plan.Steps)-1]
+		last.Tasks = append(last.Tasks, task)
+	}
+	return plan
+}
+
+// Phase returns the steps of the given phase
+func (p *Plan) Phase(phase v1alpha1.SyncPhase) []Step {
+	var steps []Step
+	for _, step := range p.Steps {
+		if step.Phase == phase {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// String renders the plan with one line per step and one indented line per task
+func (p *Plan) String() string {
+	b := &strings.Builder{}
+	for _, step := range p.Steps {
+		fmt.Fprintf(b, "%s wave %d:\n", step.Phase, step.Wave)
+		for _, task := range step.Tasks {
+			fmt.Fprintf(b, "  %s", resourceKey(task.Object))
+			if task.HookType != "" {
+				fmt.Fprintf(b, " (%s hook)", task.HookType)
+			}
+			b.WriteString("\n")
+		}
+	}
+	for _, obj := range p.Skipped {
+		fmt.Fprintf(b, "skipped: %s\n", resourceKey(obj))
+	}
+	return b.String()
+}
+
+// sortTasks orders tasks by phase, wave, kind and name, the same way Argo CD does. Tasks that compare equal
+// keep their input order.
+func sortTasks(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if d := a.Phase.Order() - b.Phase.Order(); d != 0 {
+			return d < 0
+		}
+		if d := a.Wave - b.Wave; d != 0 {
+			return d < 0
+		}
+		if d := kindOrder[a.Object.GetKind()] - kindOrder[b.Object.GetKind()]; d != 0 {
+			return d < 0
+		}
+		return a.Object.GetName() < b.Object.GetName()
+	})
+}
+
+func resourceKey(obj *unstructured.Unstructured) string {
+	gk := obj.GroupVersionKind().GroupKind()
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	return fmt.Sprintf("%s %s", gk.String(), name)
+}
@@ -0,0 +1,154 @@
+package tracking
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/util/appname"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceTracking reads and writes tracking metadata with the settings of an Argo CD installation. Its zero
+// value uses the label method with the default label key.
+type ResourceTracking struct {
+	// Method is the tracking method, label if empty
+	Method TrackingMethod
+	// LabelKey is the label used to track resources, LabelKeyAppInstance if empty
+	LabelKey string
+	// InstallationID is the installation id of the Argo CD instance, if configured. Resources stamped with
+	// another installation id are not tracked by it.
+	InstallationID string
+	// ControlPlaneNamespace is the namespace Argo CD runs in, used to format the instance names of
+	// applications in other namespaces unless the application status reports the controller namespace
+	ControlPlaneNamespace string
+}
+
+func (t ResourceTracking) method() TrackingMethod {
+	return TrackingMethodOrDefault(string(t.Method))
+}
+
+func (t ResourceTracking) labelKey() string {
+	if t.LabelKey == "" {
+		return LabelKeyAppInstance
+	}
+	return t.LabelKey
+}
+
+// instanceName returns the instance name of app, preferring the control plane namespace the controller
+// reported in the application status
+func (t ResourceTracking) instanceName(app *v1alpha1.Application) string {
+	return InstanceName(app, appname.ControlPlaneNamespace(app, t.ControlPlaneNamespace))
+}
+
+// GetAppName returns the instance name of the application that tracks obj, or an empty string if obj is not
+// tracked. With the annotation methods, tracking ids that refer to another object are ignored.
+func (t ResourceTracking) GetAppName(obj *unstructured.Unstructured) string {
+	if t.InstallationID != obj.GetAnnotations()[AnnotationInstallationID] {
+		return ""
+	}
+	switch t.method() {
+	case TrackingMethodAnnotation, TrackingMethodAnnotationAndLabel:
+		value, err := ParseAppInstanceValue(obj.GetAnnotations()[AnnotationKeyAppInstance])
+		if err != nil || !value.Matches(obj) {
+			return ""
+		}
+		return value.ApplicationName
+	default:
+		return obj.GetLabels()[t.labelKey()]
+	}
+}
+
+// IsTrackedBy returns true if obj belongs to app
+func (t ResourceTracking) IsTrackedBy(obj *unstructured.Unstructured, app *v1alpha1.Application) bool {
+	name := t.GetAppName(obj)
+	return name != "" && name == t.instanceName(app)
+}
+
+// SetAppInstance stamps obj with the tracking metadata of app. With annotation+label the label holds the
+// instance name truncated to the maximum label length.
+func (t ResourceTracking) SetAppInstance(obj *unstructured.Unstructured, app *v1alpha1.Application) error {
+	instanceName := t.instanceName(app)
+	if t.InstallationID != "" {
+		setAnnotation(obj, AnnotationInstallationID, t.InstallationID)
+	}
+	switch t.method() {
+	case TrackingMethodLabel:
+		if len(instanceName) > maxLabelValueLength {
+			return fmt.Errorf("instance name %q is longer than %d characters and cannot be tracked with a label, use annotation tracking instead", instanceName, maxLabelValueLength)
+		}
+		setLabel(obj, t.labelKey(), instanceName)
+	case TrackingMethodAnnotation:
+		setAnnotation(obj, AnnotationKeyAppInstance, NewAppInstanceValue(obj, instanceName).String())
+	case TrackingMethodAnnotationAndLabel:
+		setAnnotation(obj, AnnotationKeyAppInstance, NewAppInstanceValue(obj, instanceName).String())
+		setLabel(obj, t.labelKey(), instanceName[:min(len(instanceName), maxLabelValueLength)])
+	default:
+		return fmt.Errorf("unknown resource tracking method %q", t.Method)
+	}
+	return nil
+}
+
+// RemoveAppInstance removes all tracking metadata from obj
+func (t ResourceTracking) RemoveAppInstance(obj *unstructured.Unstructured) {
+	labels := obj.GetLabels()
+	delete(labels, t.labelKey())
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	delete(annotations, AnnotationKeyAppInstance)
+	delete(annotations, AnnotationInstallationID)
+	obj.SetAnnotations(annotations)
+}
+
+// ConflictError is returned if an object is already tracked by another application
+type ConflictError struct {
+	// Owner is the instance name of the application that tracks the object
+	Owner string
+	// Object describes the object
+	Object string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s is already tracked by application %s", e.Object, e.Owner)
+}
+
+// CheckConflict returns a ConflictError if obj is tracked by an application other than app. With
+// annotation+label tracking, a label of another application next to the tracking id of app is reported as
+// well. Metadata the tracking method does not use is ignored, as other tools set the instance label too.
+func (t ResourceTracking) CheckConflict(obj *unstructured.Unstructured, app *v1alpha1.Application) error {
+	instanceName := t.instanceName(app)
+	object := fmt.Sprintf("%s %s", obj.GroupVersionKind().GroupKind(), objectName(obj))
+	if owner := t.GetAppName(obj); owner != "" && owner != instanceName {
+		return &ConflictError{Owner: owner, Object: object}
+	}
+	if t.method() == TrackingMethodAnnotationAndLabel {
+		if label := obj.GetLabels()[t.labelKey()]; label != "" && label != instanceName[:min(len(instanceName), maxLabelValueLength)] {
+			return &ConflictError{Owner: label, Object: object}
+		}
+	}
+	return nil
+}
+
+func objectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func setLabel(obj *unstructured.Unstructured, key, value string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[key] = value
+	obj.SetLabels(labels)
+}
+
+func setAnnotation(obj *unstructured.Unstructured, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
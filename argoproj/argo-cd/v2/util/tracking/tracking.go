@@ -0,0 +1,124 @@
+// Package tracking reads and writes the metadata Argo CD uses to track which Application a resource belongs to.
+package tracking
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/util/appname"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TrackingMethod is the way Argo CD tracks the resources of an application, configured by
+// application.resourceTrackingMethod in argocd-cm
+type TrackingMethod string
+
+const (
+	// TrackingMethodLabel tracks resources with the app instance label
+	TrackingMethodLabel TrackingMethod = "label"
+	// TrackingMethodAnnotation tracks resources with the tracking-id annotation
+	TrackingMethodAnnotation TrackingMethod = "annotation"
+	// TrackingMethodAnnotationAndLabel tracks resources with the tracking-id annotation and sets the label for
+	// informational purposes
+	TrackingMethodAnnotationAndLabel TrackingMethod = "annotation+label"
+)
+
+const (
+	// LabelKeyAppInstance is the default label key used to track resources
+	LabelKeyAppInstance = "app.kubernetes.io/instance"
+	// AnnotationKeyAppInstance is the annotation holding the tracking id
+	AnnotationKeyAppInstance = "argocd.argoproj.io/tracking-id"
+	// AnnotationInstallationID holds the id of the Argo CD installation that tracks a resource, if configured
+	AnnotationInstallationID = "argocd.argoproj.io/installation-id"
+
+	// maxLabelValueLength is the maximum length of a label value
+	maxLabelValueLength = 63
+	// appNamespaceSeparator separates the namespace and the name of applications outside the control plane
+	// namespace in instance names
+	appNamespaceSeparator = "_"
+)
+
+// ErrWrongTrackingFormat is returned for tracking ids that do not have the format app:group/kind:namespace/name
+var ErrWrongTrackingFormat = errors.New("wrong resource tracking format, should be <application-name>:<group>/<kind>:<namespace>/<name>")
+
+// TrackingMethodOrDefault returns the given method or the default label method if it is empty
+func TrackingMethodOrDefault(method string) TrackingMethod {
+	if method == "" {
+		return TrackingMethodLabel
+	}
+	return TrackingMethod(method)
+}
+
+// InstanceName returns the name Argo CD uses for the application in tracking metadata. Applications outside
+// the control plane namespace are prefixed with their namespace.
+func InstanceName(app *v1alpha1.Application, controlPlaneNamespace string) string {
+	return appname.FromApplication(app).InstanceName(controlPlaneNamespace)
+}
+
+// ParseInstanceName splits an instance name into namespace and name. Names without namespace belong to the
+// control plane namespace. Unlike appname.Parse it only splits at _, as instance names are label values and
+// cannot contain /, so a name such as team/app is returned as is in the control plane namespace.
+func ParseInstanceName(instanceName, controlPlaneNamespace string) (namespace, name string) {
+	if namespace, name, ok := strings.Cut(instanceName, appNamespaceSeparator); ok {
+		return namespace, name
+	}
+	return controlPlaneNamespace, instanceName
+}
+
+// AppInstanceValue is a parsed tracking id
+type AppInstanceValue struct {
+	ApplicationName string
+	Group           string
+	Kind            string
+	Namespace       string
+	Name            string
+}
+
+// String formats the tracking id as app:group/kind:namespace/name
+func (v AppInstanceValue) String() string {
+	return fmt.Sprintf("%s:%s/%s:%s/%s", v.ApplicationName, v.Group, v.Kind, v.Namespace, v.Name)
+}
+
+// GroupKind returns the group kind the tracking id refers to
+func (v AppInstanceValue) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: v.Group, Kind: v.Kind}
+}
+
+// ParseAppInstanceValue parses a tracking id
+func ParseAppInstanceValue(value string) (*AppInstanceValue, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return nil, ErrWrongTrackingFormat
+	}
+	group, kind, ok := strings.Cut(parts[1], "/")
+	if !ok || kind == "" {
+		return nil, ErrWrongTrackingFormat
+	}
+	namespace, name, ok := strings.Cut(parts[2], "/")
+	if !ok || name == "" {
+		return nil, ErrWrongTrackingFormat
+	}
+	return &AppInstanceValue{ApplicationName: parts[0], Group: group, Kind: kind, Namespace: namespace, Name: name}, nil
+}
+
+// NewAppInstanceValue returns the tracking id of obj for the application with the given instance name
+func NewAppInstanceValue(obj *unstructured.Unstructured, instanceName string) AppInstanceValue {
+	gvk := obj.GroupVersionKind()
+	return AppInstanceValue{
+		ApplicationName: instanceName,
+		Group:           gvk.Group,
+		Kind:            gvk.Kind,
+		Namespace:       obj.GetNamespace(),
+		Name:            obj.GetName(),
+	}
+}
+
+// Matches returns true if the tracking id refers to obj. Argo CD ignores tracking ids that refer to another
+// object, such as ids copied along with the metadata of the object that created obj.
+func (v AppInstanceValue) Matches(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return v.Group == gvk.Group && v.Kind == gvk.Kind && v.Namespace == obj.GetNamespace() && v.Name == obj.GetName()
+}
@@ -0,0 +1,17 @@
+package tracking
+
+import "testing"
+
+func TestParseInstanceName(t *testing.T) {
+	for _, tc := range []struct {
+		instanceName, namespace, name string
+	}{
+		{instanceName: "app", namespace: "argocd", name: "app"},
+		{instanceName: "team_app", namespace: "team", name: "app"},
+		{instanceName: "team/app", namespace: "argocd", name: "team/app"},
+	} {
+		if namespace, name := ParseInstanceName(tc.instanceName, "argocd"); namespace != tc.namespace || name != tc.name {
+			t.Errorf("ParseInstanceName(%q) = %q, %q, want %q, %q", tc.instanceName, namespace, name, tc.namespace, tc.name)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Package wait blocks until an Application reaches a target state, watching it through a controller-runtime
+// client instead of polling.
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperationExpectation is the expected outcome of the operation of an Application
+type OperationExpectation string
+
+const (
+	// OperationAny does not wait for an operation
+	OperationAny OperationExpectation = ""
+	// OperationCompleted waits until the operation completed, successfully or not
+	OperationCompleted OperationExpectation = "Completed"
+	// OperationSucceeded waits until the operation succeeded and fails as soon as it completed unsuccessfully
+	OperationSucceeded OperationExpectation = "Succeeded"
+)
+
+// ErrOperationFailed is returned when the operation completed unsuccessfully while OperationSucceeded is expected
+var ErrOperationFailed = errors.New("operation did not succeed")
+
+// ErrApplicationDeleted is returned when the application is deleted while waiting for it
+var ErrApplicationDeleted = errors.New("application was deleted")
+
+// Condition is the target state of an Application. All set fields must be met.
+type Condition struct {
+	// Health holds the accepted health statuses, any status is accepted if empty
+	Health []v1alpha1.HealthStatusCode
+	// SyncStatus holds the accepted sync statuses, any status is accepted if empty
+	SyncStatus []v1alpha1.SyncStatusCode
+	// Operation is the expected outcome of the operation. An operation is completed once no operation is
+	// requested anymore and the operation state has finished.
+	Operation OperationExpectation
+	// Revision is the revision Status.Sync.Revision must have, if set
+	Revision string
+	// Revisions are the revisions Status.Sync.Revisions must have, if set
+	Revisions []string
+}
+
+// Unmet returns a description of each part of the condition the application does not meet
+func (c Condition) Unmet(app *v1alpha1.Application) []string {
+	var unmet []string
+	if len(c.Health) > 0 && !slices.Contains(c.Health, app.Status.Health.Status) {
+		unmet = append(unmet, fmt.Sprintf("health is %q, waiting for %v", app.Status.Health.Status, c.Health))
+	}
+	if len(c.SyncStatus) > 0 && !slices.Contains(c.SyncStatus, app.Status.Sync.Status) {
+		unmet = append(unmet, fmt.Sprintf("sync status is %q, waiting for %v", app.Status.Sync.Status, c.SyncStatus))
+	}
+	if c.Operation != OperationAny && !operationCompleted(app) {
+		unmet = append(unmet, "operation has not completed")
+	}
+	if c.Revision != "" && app.Status.Sync.Revision != c.Revision {
+		unmet = append(unmet, fmt.Sprintf("revision is %q, waiting for %q", app.Status.Sync.Revision, c.Revision))
+	}
+	if len(c.Revisions) > 0 && !slices.Equal(app.Status.Sync.Revisions, c.Revisions) {
+		unmet = append(unmet, fmt.Sprintf("revisions are %v, waiting for %v", app.Status.Sync.Revisions, c.Revisions))
+	}
+	return unmet
+}
+
+// Met returns true if the application meets the condition
+func (c Condition) Met(app *v1alpha1.Application) bool {
+	return len(c.Unmet(app)) == 0
+}
+
+func operationCompleted(app *v1alpha1.Application) bool {
+	state := app.Status.OperationState
+	return app.Operation == nil && state != nil && state.FinishedAt != nil && state.IsCompleted()
+}
+
+// Options controls how Application waits
+type Options struct {
+	// OnUpdate is called with every observed version of the application and the parts of the condition it
+	// does not meet yet
+	OnUpdate func(app *v1alpha1.Application, unmet []string)
+}
+
+// Application waits until the application with the given key meets the condition and returns it. It returns
+// the last observed application together with an error if ctx is done, the application is deleted, or the
+// operation failed while OperationSucceeded is expected.
+func Application(ctx context.Context, c client.WithWatch, key client.ObjectKey, condition Condition, opts Options) (*v1alpha1.Application, error) {
+	var app *v1alpha1.Application
+	var delay time.Duration
+	for {
+		// start watching before getting the application, so no change in between is missed
+		watcher, err := c.Watch(ctx, &v1alpha1.ApplicationList{},
+			client.InNamespace(key.Namespace), client.MatchingFields{"metadata.name": key.Name})
+		if err != nil {
+			return app, fmt.Errorf("failed to watch applications: %w", err)
+		}
+
+		current := &v1alpha1.Application{}
+		if err := c.Get(ctx, key, current); err != nil {
+			watcher.Stop()
+			return app, fmt.Errorf("failed to get application %s: %w", key, err)
+		}
+		app = current
+		if done, err := observe(app, condition, opts); done {
+			watcher.Stop()
+			return app, err
+		}
+
+		var received bool
+		app, received, err = consume(ctx, watcher, key, app, condition, opts)
+		watcher.Stop()
+		if !errors.Is(err, errWatchClosed) {
+			return app, err
+		}
+
+		// watches end regularly and are restarted right away, unless they failed or ended without delivering
+		// anything, which backs off to not hammer the API server
+		if received && !errors.Is(err, errWatchFailed) {
+			delay = 0
+			continue
+		}
+		delay = min(max(2*delay, minRetryDelay), maxRetryDelay)
+		select {
+		case <-ctx.Done():
+			return app, fmt.Errorf("timed out waiting for application %s: %w", key, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+const (
+	minRetryDelay = 100 * time.Millisecond
+	maxRetryDelay = 10 * time.Second
+)
+
+var (
+	errWatchClosed = errors.New("watch closed")
+	errWatchFailed = fmt.Errorf("%w with an error", errWatchClosed)
+)
+
+// consume processes watch events until the condition is met, an error occurs or the watch closes. It also
+// returns whether the watch delivered any event.
+func consume(ctx context.Context, watcher watch.Interface, key client.ObjectKey, app *v1alpha1.Application, condition Condition, opts Options) (*v1alpha1.Application, bool, error) {
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return app, received, fmt.Errorf("timed out waiting for application %s: %w", key, ctx.Err())
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return app, received, errWatchClosed
+			}
+			switch event.Type {
+			case watch.Error:
+				return app, received, errWatchFailed
+			case watch.Added, watch.Modified, watch.Deleted:
+				received = true
+				// the field selector is not honored by every client, such as the fake client
+				updated, ok := event.Object.(*v1alpha1.Application)
+				if !ok || updated.Name != key.Name {
+					continue
+				}
+				app = updated
+				if event.Type == watch.Deleted {
+					return app, received, fmt.Errorf("%w: %s", ErrApplicationDeleted, key)
+				}
+				if done, err := observe(app, condition, opts); done {
+					return app, received, err
+				}
+			}
+		}
+	}
+}
+
+// observe reports the application and returns true if waiting is done
+func observe(app *v1alpha1.Application, condition Condition, opts Options) (bool, error) {
+	unmet := condition.Unmet(app)
+	if opts.OnUpdate != nil {
+		opts.OnUpdate(app, unmet)
+	}
+	if condition.Operation == OperationSucceeded && operationCompleted(app) && !app.Status.OperationState.Successful() {
+		state := app.Status.OperationState
+		return true, fmt.Errorf("%w: phase %s: %s", ErrOperationFailed, state.Phase, state.Message)
+	}
+	return len(unmet) == 0, nil
+}
@@ -0,0 +1,144 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/external-types/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var key = client.ObjectKey{Namespace: "argocd", Name: "app"}
+
+func newClient(t *testing.T, objs ...client.Object) client.WithWatch {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newApp() *v1alpha1.Application {
+	app := &v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	app.Status.Health.Status = v1alpha1.HealthStatusProgressing
+	app.Status.Sync.Status = v1alpha1.SyncStatusCodeOutOfSync
+	return app
+}
+
+// update changes the application once the wait observed it, so the change arrives through the watch
+func update(t *testing.T, c client.Client, mutate func(app *v1alpha1.Application)) func(*v1alpha1.Application, []string) {
+	var done atomic.Bool
+	return func(*v1alpha1.Application, []string) {
+		if done.Swap(true) {
+			return
+		}
+		go func() {
+			app := &v1alpha1.Application{}
+			if err := c.Get(context.Background(), key, app); err != nil {
+				t.Error(err)
+				return
+			}
+			mutate(app)
+			if err := c.Update(context.Background(), app); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+}
+
+func TestApplication(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := newClient(t, newApp())
+	condition := Condition{
+		Health:     []v1alpha1.HealthStatusCode{v1alpha1.HealthStatusHealthy},
+		SyncStatus: []v1alpha1.SyncStatusCode{v1alpha1.SyncStatusCodeSynced},
+	}
+	app, err := Application(ctx, c, key, condition, Options{OnUpdate: update(t, c, func(app *v1alpha1.Application) {
+		app.Status.Health.Status = v1alpha1.HealthStatusHealthy
+		app.Status.Sync.Status = v1alpha1.SyncStatusCodeSynced
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !condition.Met(app) {
+		t.Errorf("returned application does not meet the condition: %v", condition.Unmet(app))
+	}
+}
+
+func TestApplicationOperationFailed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := newClient(t, newApp())
+	_, err := Application(ctx, c, key, Condition{Operation: OperationSucceeded}, Options{OnUpdate: update(t, c, func(app *v1alpha1.Application) {
+		now := metav1.Now()
+		app.Status.OperationState = &v1alpha1.OperationState{Phase: v1alpha1.OperationFailed, Message: "boom", FinishedAt: &now}
+	})})
+	if !errors.Is(err, ErrOperationFailed) {
+		t.Fatalf("expected operation failed error, got %v", err)
+	}
+}
+
+func TestApplicationDeleted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := newClient(t, newApp())
+	var deleted atomic.Bool
+	_, err := Application(ctx, c, key, Condition{Health: []v1alpha1.HealthStatusCode{v1alpha1.HealthStatusHealthy}}, Options{
+		OnUpdate: func(app *v1alpha1.Application, _ []string) {
+			if !deleted.Swap(true) {
+				go func() { _ = c.Delete(context.Background(), newApp()) }()
+			}
+		},
+	})
+	if !errors.Is(err, ErrApplicationDeleted) {
+		t.Fatalf("expected application deleted error, got %v", err)
+	}
+}
+
+func TestApplicationTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	app, err := Application(ctx, newClient(t, newApp()), key, Condition{Health: []v1alpha1.HealthStatusCode{v1alpha1.HealthStatusHealthy}}, Options{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if app == nil || app.Name != key.Name {
+		t.Errorf("expected the last observed application, got %v", app)
+	}
+}
+
+// failingWatchClient returns watches that fail right away
+type failingWatchClient struct {
+	client.WithWatch
+	watches atomic.Int32
+}
+
+func (c *failingWatchClient) Watch(context.Context, client.ObjectList, ...client.ListOption) (watch.Interface, error) {
+	c.watches.Add(1)
+	watcher := watch.NewFake()
+	go watcher.Error(&metav1.Status{Message: "failed"})
+	return watcher, nil
+}
+
+func TestApplicationWatchErrorBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	c := &failingWatchClient{WithWatch: newClient(t, newApp())}
+	_, err := Application(ctx, c, key, Condition{Health: []v1alpha1.HealthStatusCode{v1alpha1.HealthStatusHealthy}}, Options{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	// 100ms, 200ms and 400ms delays fit into the timeout
+	if watches := c.watches.Load(); watches > 4 {
+		t.Errorf("expected backoff between failed watches, got %d watches", watches)
+	}
+}